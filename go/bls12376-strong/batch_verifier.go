@@ -0,0 +1,165 @@
+package bls12376strong
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// ErrStreamingDomainTag is returned by BatchVerifier.Verify when
+// BatchOptions.DomainTag is set: the Fiat–Shamir mode derives the random
+// scalars from all the points, which are not known while streaming.
+var ErrStreamingDomainTag = batch.ErrStreamingDomainTag
+
+// ErrVerified is returned by BatchVerifier.Add, BatchVerifier.AddMany and
+// BatchVerifier.Verify once Verify has been called.
+var ErrVerified = batch.ErrVerified
+
+// BatchVerifier checks that a stream of points P_i are in G1, as
+// IsInSubGroupBatch does for a slice, without buffering the points.
+// Each point is checked with the Tate pairings when it is added, and is then
+// accumulated in the buckets of the random linear combinations Sj=∑[s_i]P_i,
+// so that the memory does not depend on the number of points.
+//
+// A BatchVerifier checks a single batch: Verify is terminal, see Verify.
+// A BatchVerifier is not safe for concurrent use.
+type BatchVerifier struct {
+	roundsBits []int
+	chunks     []batchVerifierChunk // the chunks of all the rounds, round after round
+	nbPoints   int
+	rejected   bool // a point is not on the curve or not on E[r*e']
+	verified   bool // Verify has been called
+	err        error
+}
+
+// batchVerifierWindowSize is the number of digits read at once from a source.
+const batchVerifierWindowSize = 64
+
+// batchVerifierChunk accumulates one (msmC-1)-bit digit of the random scalars
+// of a linear combination, as processChunkG1Simplified does.
+type batchVerifierChunk struct {
+	rng     io.Reader
+	mask    uint16
+	br      [batchVerifierWindowSize * 2]byte
+	buckets bucketg1JacExtendedC6
+}
+
+// NewBatchVerifier returns an empty BatchVerifier. Errors due to opts are
+// reported by Verify.
+func NewBatchVerifier(opts BatchOptions) *BatchVerifier {
+	v := new(BatchVerifier)
+	if len(opts.DomainTag) != 0 {
+		v.err = ErrStreamingDomainTag
+		return v
+	}
+	if v.roundsBits, v.err = opts.roundsBits(); v.err != nil {
+		return v
+	}
+	for _, nbBits := range v.roundsBits {
+		sources, err := opts.randomSources(msmNbChunks(nbBits))
+		if err != nil {
+			v.err = err
+			return v
+		}
+		for j := range sources {
+			// the most significant digit may be shorter
+			digitBits := min(msmC-1, nbBits-j*(msmC-1))
+			chunk := batchVerifierChunk{rng: sources[j], mask: uint16((1 << digitBits) - 1)}
+			for k := range chunk.buckets {
+				chunk.buckets[k].SetInfinity()
+			}
+			v.chunks = append(v.chunks, chunk)
+		}
+	}
+	return v
+}
+
+// Add checks that p is on the curve and on E[r*e'] and adds it to the random
+// linear combinations.
+// It returns ErrVerified if Verify has been called.
+func (v *BatchVerifier) Add(p G1Affine) error {
+	if v.verified {
+		return ErrVerified
+	}
+	v.add(&p)
+	return nil
+}
+
+// AddMany adds the points one after the other, see Add.
+func (v *BatchVerifier) AddMany(points []G1Affine) error {
+	if v.verified {
+		return ErrVerified
+	}
+	for i := range points {
+		v.add(&points[i])
+	}
+	return nil
+}
+
+func (v *BatchVerifier) add(p *G1Affine) {
+	if v.err != nil || v.rejected {
+		return
+	}
+
+	// 1. Check p is on the curve and on E[r*e']
+	if checkPoint(p) != nil {
+		v.rejected = true
+		return
+	}
+
+	// 2. Add the digits of [s_i]p to the buckets of Sj
+	pos := v.nbPoints % batchVerifierWindowSize
+	for k := range v.chunks {
+		chunk := &v.chunks[k]
+		if pos == 0 {
+			chunk.rng.Read(chunk.br[:]) // crypto/rand and ChaCha8 do not return an error, always fill br
+		}
+		digit := binary.LittleEndian.Uint16(chunk.br[2*pos:]) & chunk.mask
+		if digit != 0 {
+			chunk.buckets[digit-1].addMixed(p)
+		}
+	}
+	v.nbPoints++
+}
+
+// Verify reports whether all the points added are in G1, with the soundness
+// of IsInSubGroupBatch for the same options.
+//
+// Verify is terminal: the random scalars of the points added after it would
+// be drawn from the same streams, whose combinations with the points already
+// added have been checked, so Add, AddMany and Verify return ErrVerified once
+// it has been called. A new batch needs a new BatchVerifier.
+//
+// It returns an error if opts is invalid, if opts.DomainTag is set or if
+// opts.Rand fails.
+func (v *BatchVerifier) Verify() (bool, error) {
+	if v.verified {
+		return false, ErrVerified
+	}
+	v.verified = true
+	if v.err != nil {
+		return false, v.err
+	}
+	if v.rejected {
+		return false, nil
+	}
+
+	// Check Sj are on E[r]
+	chunks := v.chunks
+	for _, nbBits := range v.roundsBits {
+		chChunks := make([]chan g1JacExtended, msmNbChunks(nbBits))
+		for j := range chChunks {
+			chChunks[j] = make(chan g1JacExtended, 1)
+			chChunks[j] <- reduceBuckets(chunks[j].buckets[:])
+		}
+		chunks = chunks[len(chChunks):]
+
+		var p G1Jac
+		msmReduceChunkG1Affine(&p, msmC-1, chChunks)
+		if !p.IsInSubGroup() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
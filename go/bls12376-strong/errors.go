@@ -0,0 +1,39 @@
+package bls12376strong
+
+import (
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// Reasons for which CheckSubGroupBatch rejects a batch. They are wrapped in a
+// *BatchError and can be matched with errors.Is. The reasons that do not
+// depend on the curve are shared with the other curve packages.
+var (
+	// ErrNotOnCurve means that a point is not on the curve.
+	ErrNotOnCurve = batch.ErrNotOnCurve
+
+	// ErrTorsion2 means that a point fails the Tate pairings test of order 2:
+	// it has a non-trivial component of order 2.
+	ErrTorsion2 = batch.ErrTorsion2
+
+	// ErrTorsion3 means that a point fails the Tate pairings test of order 3:
+	// it has a non-trivial component of order 3.
+	ErrTorsion3 = batch.ErrTorsion3
+
+	// ErrRandomCombination means that all the points passed the Tate
+	// pairings test but a random linear combination of them is not in G1:
+	// some point has a non-trivial component of order 1443790552614742699.
+	// FindNonMembers locates such points.
+	ErrRandomCombination = batch.ErrRandomCombination
+)
+
+// BatchError is the error returned by CheckSubGroupBatch when a batch is
+// rejected. Its Index is the index of the first offending point, or -1 if it
+// is not known, as for ErrRandomCombination, and its Err is one of
+// ErrNotOnCurve, ErrTorsion2, ErrTorsion3 or ErrRandomCombination.
+type BatchError = batch.Error
+
+// randomCombinationError returns the error of CheckSubGroupBatch for the
+// result of the random linear combinations check.
+func randomCombinationError(ok bool, err error) error {
+	return batch.CombinationError(ok, err, ErrRandomCombination)
+}
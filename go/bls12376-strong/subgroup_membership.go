@@ -1,9 +1,12 @@
 package bls12376strong
 
 import (
-	"crypto/rand"
+	"context"
+	"encoding/binary"
+	"io"
+	"sort"
+	"sync"
 	"sync/atomic"
-	"unsafe"
 
 	"github.com/yelhousni/batch-subgroup-membership/go/bls12376-strong/fp"
 	"github.com/yelhousni/batch-subgroup-membership/go/parallel"
@@ -37,56 +40,262 @@ func IsInSubGroupBatchNaiveParallel(points []G1Affine) bool {
 }
 
 // IsInSubGroupBatch checks if a batch of points P_i are in G1.
-// First, it checks that all points are on a larger torsion E[r*e'] using Tate
-// pairings [Koshelev22].
+// First, it checks that all points are on the curve and on a larger torsion
+// E[r*e'] using Tate pairings [Koshelev22]. The point at infinity, which is in
+// G1, skips the pairings.
 // Second, it generates random scalars s_i in the range [0, 2^60[, performs
-// n=rounds multi-scalar-multiplication Sj=∑[s_i]P_i of sizes N=len(points) and
-// checks if Sj are on E[r] using Scott test [Scott21].
+// n=⌈β/60⌉ multi-scalar-multiplication Sj=∑[s_i]P_i of sizes N=len(points) and
+// checks if Sj are on E[r] using Scott test [Scott21], where β is the security
+// level of opts.
+//
+// Points in Jacobian coordinates can be converted with BatchJacobianToAffineG1
+// first: its batch inversion costs less than 1% of the check.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
 //
 // [Koshelev22]: https://eprint.iacr.org/2022/037.pdf
 // [Scott21]: https://eprint.iacr.org/2021/1130.pdf
-func IsInSubGroupBatch(points []G1Affine, rounds int) bool {
+func IsInSubGroupBatch(points []G1Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchContext(context.Background(), points, opts)
+}
 
-	// 1. Check points are on E[r*e']
+// IsInSubGroupBatchContext is like IsInSubGroupBatch but gives up when ctx is
+// done: the Tate pairings loop and the multi-scalar-multiplication goroutines
+// stop promptly and ctx.Err() is returned.
+func IsInSubGroupBatchContext(ctx context.Context, points []G1Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve and on E[r*e']
 	for i := range points {
-		// 1.1. Tate_{2,P2}(Q) == Tate_{2,P2'}(Q) == 1, with P2 and P2' a basis of E[2].
-		if !isFirstTateOne(points[i]) {
-			return false
+		if err := ctx.Err(); err != nil {
+			return false, err
 		}
-		// 1.2. Tate_{3,P3}(Q) == 1, with P3 of order 3.
-		if !isSecondTateOne(points[i]) {
-			return false
+		if checkPoint(&points[i]) != nil {
+			return false, nil
 		}
 	}
 
-	// 2. Check S1 is on E[r]
-	return _msmCheck(points)
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return msmCheckRounds(ctx, points, roundsBits, &opts)
+}
+
+func IsInSubGroupBatchParallel(points []G1Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchParallelContext(context.Background(), points, opts)
 }
 
-func IsInSubGroupBatchParallel(points []G1Affine, rounds int) bool {
+// IsInSubGroupBatchParallelContext is like IsInSubGroupBatchParallel but gives
+// up when ctx is done, see IsInSubGroupBatchContext.
+func IsInSubGroupBatchParallelContext(ctx context.Context, points []G1Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return false, err
+	}
 
-	// 1. Check points are on E[r*e']
+	// 1. Check points are on the curve and on E[r*e']
 	var nbErrors int64
-	parallel.Execute(len(points), func(start, end int) {
+	err = parallel.ExecuteContext(ctx, len(points), func(start, end int) {
 		for i := start; i < end; i++ {
-			// 1.1. Tate_{2,P2}(Q) == Tate_{2,P2'}(Q) == 1, with P2 and P2' a basis of E[2].
-			if !isFirstTateOne(points[i]) {
-				atomic.AddInt64(&nbErrors, 1)
+			if ctx.Err() != nil {
 				return
 			}
-			// 1.2. Tate_{3,P3}(Q) == 1, with P3 of order 3.
-			if !isSecondTateOne(points[i]) {
+			if checkPoint(&points[i]) != nil {
 				atomic.AddInt64(&nbErrors, 1)
 				return
 			}
 		}
 	})
+	if err != nil {
+		return false, err
+	}
 	if nbErrors > 0 {
-		return false
+		return false, nil
 	}
 
-	// 2. Check S1 is on E[r]
-	return _msmCheck(points)
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return msmCheckRounds(ctx, points, roundsBits, &opts)
+}
+
+// CheckSubGroupBatch is like IsInSubGroupBatch but explains why a batch is
+// rejected. It returns nil if all the points are in G1 and otherwise a
+// *BatchError wrapping:
+//   - ErrNotOnCurve if a point is not on the curve,
+//   - ErrTorsion2 or ErrTorsion3 if a point fails the Tate pairings test,
+//     e.g. ErrTorsion3 for the point (0,1) of order 3,
+//   - ErrRandomCombination if a random linear combination is not in G1.
+//
+// Any other error comes from opts, as for IsInSubGroupBatch.
+func CheckSubGroupBatch(points []G1Affine, opts BatchOptions) error {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return err
+	}
+
+	// 1. Check points are on the curve and on E[r*e']
+	for i := range points {
+		if err := checkPoint(&points[i]); err != nil {
+			return &BatchError{Index: i, Err: err}
+		}
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return randomCombinationError(msmCheckRounds(context.Background(), points, roundsBits, &opts))
+}
+
+func CheckSubGroupBatchParallel(points []G1Affine, opts BatchOptions) error {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return err
+	}
+
+	// 1. Check points are on the curve and on E[r*e']
+	// each worker stops at its first offending point, so the smallest one
+	// reported is the first offending point of the batch.
+	var lock sync.Mutex
+	var firstErr *BatchError
+	parallel.Execute(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if err := checkPoint(&points[i]); err != nil {
+				lock.Lock()
+				if firstErr == nil || i < firstErr.Index {
+					firstErr = &BatchError{Index: i, Err: err}
+				}
+				lock.Unlock()
+				return
+			}
+		}
+	})
+	if firstErr != nil {
+		return firstErr
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return randomCombinationError(msmCheckRounds(context.Background(), points, roundsBits, &opts))
+}
+
+// checkPoint returns the reason why p is not on the curve or not on E[r*e'],
+// or nil.
+func checkPoint(p *G1Affine) error {
+	// the point at infinity is in G1, and its encoding (0,0) is not a valid
+	// input to the Tate pairings.
+	if p.IsInfinity() {
+		return nil
+	}
+	if !p.IsOnCurve() {
+		return ErrNotOnCurve
+	}
+	// Tate_{2,P2}(Q) == Tate_{2,P2'}(Q) == 1, with P2 and P2' a basis of E[2].
+	if !isFirstTateOne(*p) {
+		return ErrTorsion2
+	}
+	// Tate_{3,P3}(Q) == 1, with P3 = (0,1) of order 3. For Q = P3 the
+	// function y-1 vanishes and its cubic symbol is 0, not 1.
+	if p.Y.IsOne() || !isSecondTateOne(*p) {
+		return ErrTorsion3
+	}
+	return nil
+}
+
+// FindNonMembers returns the indices, in increasing order, of the points P_i
+// that are not in G1.
+// First, every point that is not on the curve or fails the Tate pairings test
+// of IsInSubGroupBatch is reported directly.
+// Second, the remaining points are bisected using the random linear
+// combination check of IsInSubGroupBatch: a half that passes is discarded and
+// a half that fails is split again, down to single points that are checked
+// with Scott test [Scott21]. For k culprits among N points this costs
+// O(k·log(N)) multi-scalar-multiplications instead of N scalar
+// multiplications.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func FindNonMembers(points []G1Affine, opts BatchOptions) ([]int, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return nil, err
+	}
+
+	var nonMembers []int
+
+	// 1. Points that are not on the curve or not on E[r*e'] are not in G1
+	candidates := points
+	var indices []int // indices[k] is the index of candidates[k] in points, nil for the identity
+	for i := range points {
+		if checkPoint(&points[i]) == nil {
+			if indices != nil {
+				candidates = append(candidates, points[i])
+				indices = append(indices, i)
+			}
+			continue
+		}
+		if indices == nil {
+			// first culprit: from now on keep track of the remaining points
+			candidates = make([]G1Affine, i, len(points))
+			copy(candidates, points[:i])
+			indices = make([]int, i, len(points))
+			for k := range indices {
+				indices[k] = k
+			}
+		}
+		nonMembers = append(nonMembers, i)
+	}
+
+	// 2. Bisect the points on E[r*e'] using the random linear combination check
+	opts = opts.bindPoints(points)
+	culprits, err := bisectNonMembers(context.Background(), candidates, 0, false, roundsBits, &opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range culprits {
+		if indices != nil {
+			k = indices[k]
+		}
+		nonMembers = append(nonMembers, k)
+	}
+	sort.Ints(nonMembers)
+
+	return nonMembers, nil
+}
+
+// bisectNonMembers appends to culprits the offsets, shifted by offset, of the
+// points that are not in G1. If knownBad is set, points is already known to
+// fail the random linear combination check and it is not run again.
+func bisectNonMembers(ctx context.Context, points []G1Affine, offset int, knownBad bool, roundsBits []int, opts *BatchOptions, culprits []int) ([]int, error) {
+	if len(points) == 0 {
+		return culprits, nil
+	}
+	if len(points) == 1 {
+		// a single point is checked exactly with Scott test.
+		if !points[0].IsInSubGroup() {
+			culprits = append(culprits, offset)
+		}
+		return culprits, nil
+	}
+	if !knownBad {
+		ok, err := msmCheckRounds(ctx, points, roundsBits, opts)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return culprits, nil
+		}
+	}
+
+	// if the left half passes, the culprits are in the right half.
+	mid := len(points) / 2
+	n := len(culprits)
+	culprits, err := bisectNonMembers(ctx, points[:mid], offset, false, roundsBits, opts, culprits)
+	if err != nil {
+		return nil, err
+	}
+	return bisectNonMembers(ctx, points[mid:], offset+mid, len(culprits) == n, roundsBits, opts, culprits)
 }
 
 // ---- Tate pairings ----
@@ -96,6 +305,12 @@ func IsInSubGroupBatchParallel(points []G1Affine, rounds int) bool {
 //	Tate_{2,P2'}(Q) = (x+ω)^((p-1)/2) == 1 with ω^3 = 1 mod p.
 //
 // where P2 = (-1,0) and P2' = (-ω,0) are points of order 2 on the curve.
+//
+// The exponentiations are not computed: fp.Element.Legendre uses Pornin's
+// optimized binary GCD on fixed-width limbs instead of Euler's criterion, see
+// BenchmarkTatePairingsLegendre, and BenchmarkElementLegendrePornin and
+// BenchmarkElementLegendreExp in fp, whose tests check it against the
+// exponentiation.
 func isFirstTateOne(point G1Affine) bool {
 	var tate1, tate2, one fp.Element
 	one.SetOne()
@@ -608,10 +823,52 @@ func expByp3(x *fp.Element) *fp.Element {
 }
 
 // ---- MSM ----
-func _msmCheck(points []G1Affine) bool {
-	const nbBitsBounds = 61
-	const c = 6
-	const nbChunks = 11 //(nbBitsBounds + c - 1) / c
+
+// msmCheckRounds checks that the random linear combinations Sj=∑[s_i]P_i are on
+// E[r], where the scalars of Sj are drawn in [0, 2^roundsBits[j]) from
+// opts.Rand.
+func msmCheckRounds(ctx context.Context, points []G1Affine, roundsBits []int, opts *BatchOptions) (bool, error) {
+	for _, nbBits := range roundsBits {
+		sources, err := opts.randomSources(msmNbChunks(nbBits))
+		if err != nil {
+			return false, err
+		}
+		ok, err := _msmCheck(ctx, points, nbBits, sources)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// msmC is the window size of the random linear combinations: the random
+// scalars are split in (msmC-1)-bit digits, one per chunk.
+const msmC = 6
+
+// msmNbChunks returns the number of chunks of nbBits-bit random scalars.
+func msmNbChunks(nbBits int) int {
+	return (nbBits + msmC - 2) / (msmC - 1)
+}
+
+// _msmCheck checks that S=∑[s_i]P_i is on E[r] for random scalars s_i in
+// [0, 2^nbBits). The digits of the chunk j are read from sources[j].
+// It returns ctx.Err() if ctx is done before S is computed.
+func _msmCheck(ctx context.Context, points []G1Affine, nbBits int, sources []io.Reader) (bool, error) {
+	var p G1Jac
+	msmRandomCombination(ctx, &p, points, nbBits, sources)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return p.IsInSubGroup(), nil
+}
+
+// msmRandomCombination sets p to ∑[s_i]P_i for random scalars s_i in
+// [0, 2^nbBits) and returns p. The digits of the chunk j are read from
+// sources[j], with len(sources) == msmNbChunks(nbBits). If ctx is done the
+// chunks stop early and p is meaningless.
+func msmRandomCombination(ctx context.Context, p *G1Jac, points []G1Affine, nbBits int, sources []io.Reader) *G1Jac {
+	const c = msmC
+	nbChunks := msmNbChunks(nbBits)
 
 	// for each chunk, spawn one go routine that'll loop through all the scalars in the
 	// corresponding bit-window
@@ -623,29 +880,29 @@ func _msmCheck(points []G1Affine) bool {
 		chChunks[i] = make(chan g1JacExtended, 1)
 	}
 
-	for j := int(nbChunks - 1); j >= 0; j-- {
-		go processChunkG1Simplified[bucketg1JacExtendedC6](uint64(j), chChunks[j], c, points)
+	for j := nbChunks - 1; j >= 0; j-- {
+		// the most significant digit may be shorter
+		digitBits := min(c-1, nbBits-j*(c-1))
+		go processChunkG1Simplified[bucketg1JacExtendedC6](ctx, uint64(j), chChunks[j], uint64(digitBits), points, sources[j])
 	}
 
-	var p G1Jac
-	msmReduceChunkG1Affine(&p, int(c), chChunks[:])
-
-	return p.IsInSubGroup()
+	return msmReduceChunkG1Affine(p, c-1, chChunks[:])
 }
 
-func processChunkG1Simplified[B bucketg1JacExtendedC6](chunk uint64,
+// processChunkG1Simplified computes ∑[d_i]P_i for random digits d_i in
+// [0, 2^digitBits) read from rng, with digitBits ≤ 5, using the buckets
+// method. It stops early if ctx is done.
+func processChunkG1Simplified[B bucketg1JacExtendedC6](ctx context.Context, chunk uint64,
 	chRes chan<- g1JacExtended,
-	c uint64,
-	points []G1Affine) {
+	digitBits uint64,
+	points []G1Affine,
+	rng io.Reader) {
 
 	const windowSize = 1024
 	var br [windowSize * 2]byte
 
-	// interpret br as an array of uint16 of size windowSize/2
-	randomScalars := (*[windowSize]uint16)(unsafe.Pointer(&br[0]))
-
-	// we need a mask to get only the (c-1) lowest bits of each scalar
-	mask := uint16((1 << (c - 1)) - 1)
+	// we need a mask to get only the digitBits lowest bits of each scalar
+	mask := uint16((1 << digitBits) - 1)
 
 	var buckets B
 	for i := 0; i < len(buckets); i++ {
@@ -655,19 +912,27 @@ func processChunkG1Simplified[B bucketg1JacExtendedC6](chunk uint64,
 	// for each scalars, get the digit corresponding to the chunk we're processing.
 	for i := range points {
 		if i%windowSize == 0 {
+			if ctx.Err() != nil {
+				break
+			}
 			// fill the lowest c bits of each scalar with random bytes
-			rand.Read(br[:]) // does not return an error, always fills br
+			rng.Read(br[:]) // crypto/rand and ChaCha8 do not return an error, always fill br
 		}
-		digit := randomScalars[i%windowSize] & mask
+		// br is read as little-endian uint16 so that the digits drawn from a
+		// seeded source do not depend on the platform
+		digit := binary.LittleEndian.Uint16(br[2*(i%windowSize):]) & mask
 		if digit == 0 {
 			continue
 		}
 		buckets[digit-1].addMixed(&points[i])
 	}
 
-	// reduce buckets into total
-	// total =  bucket[0] + 2*bucket[1] + 3*bucket[2] ... + n*bucket[n-1]
+	chRes <- reduceBuckets(buckets[:])
+}
 
+// reduceBuckets returns the weighted sum of the buckets
+// total =  bucket[0] + 2*bucket[1] + 3*bucket[2] ... + n*bucket[n-1]
+func reduceBuckets(buckets []g1JacExtended) g1JacExtended {
 	var runningSum, total g1JacExtended
 	runningSum.SetInfinity()
 	total.SetInfinity()
@@ -677,6 +942,5 @@ func processChunkG1Simplified[B bucketg1JacExtendedC6](chunk uint64,
 		}
 		total.add(&runningSum)
 	}
-
-	chRes <- total
+	return total
 }
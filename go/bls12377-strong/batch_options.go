@@ -0,0 +1,132 @@
+package bls12377strong
+
+import (
+	"hash"
+	"io"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// Let h be the cofactor of (E/𝔽p).
+// h = 3 * (2 * 1553806976791259819)²
+// The Tate pairings test of checkPoint rejects every point with a non-trivial
+// component of order 2 or 3: the pairings with P2, P2' and P3 are
+// non-degenerate, x+1 and x+ω only vanish at points of order 2, whose
+// Legendre symbol 0 is rejected, and y-1 only vanishes at P3 = (0,1), whose
+// cubic symbol 0 is trivial, so that checkPoint rejects P3 explicitly (see
+// TestTatePairingsSmallTorsion). Hence, after the Tate pairings test, a point
+// that is not in G1 has a non-trivial component of order 1553806976791259819.
+// The random scalars of a linear combination are drawn uniformly in
+// [0, 2^boundBits) with 2^60 < 1553806976791259819, so they are distinct modulo
+// this prime and such a point survives one combination with probability at
+// most 2⁻⁶⁰.
+//
+// In the Fiat–Shamir mode (see BatchOptions.DomainTag) the bound above holds
+// for one batch, see batch.Transcript for an adversary that can try many.
+const boundBits = 60
+
+const (
+	// DefaultSecurityLevel is the soundness in bits of the batch methods when
+	// BatchOptions.SecurityLevel is zero. It is the largest level reached with
+	// a single random linear combination.
+	DefaultSecurityLevel = boundBits
+
+	// MaxSecurityLevel is the largest soundness in bits accepted by the batch
+	// methods.
+	MaxSecurityLevel = batch.MaxSecurityLevel
+)
+
+// ErrInvalidSecurityLevel is returned by the batch methods when
+// BatchOptions.SecurityLevel is not in [0, MaxSecurityLevel].
+var ErrInvalidSecurityLevel = batch.ErrInvalidSecurityLevel
+
+// BatchOptions configures the batch subgroup membership methods.
+// The zero value selects the default settings.
+type BatchOptions struct {
+	// SecurityLevel is the target soundness β in bits: a batch that contains
+	// a point not in G1 is accepted with probability at most 2⁻ᵝ. Zero
+	// selects DefaultSecurityLevel.
+	SecurityLevel int
+
+	// Rand is the source of the random scalars. It is only read from the
+	// calling goroutine. If nil, crypto/rand.Reader is used. A seeded
+	// math/rand/v2.ChaCha8 gives reproducible draws.
+	Rand io.Reader
+
+	// DomainTag, if not empty, selects the Fiat–Shamir mode: the random
+	// scalars are derived from a hash of DomainTag and of the serialized
+	// points instead of being read from Rand, so that the decision only
+	// depends on the input and can be replayed, e.g. by every node of a
+	// consensus protocol. DomainTag should be unique to the application.
+	DomainTag []byte
+}
+
+// roundsBits returns, for each random linear combination, the bit size of its
+// random scalars. For a failure probability of 2⁻ᵝ we need rounds=⌈β/60⌉, the
+// last round only needs the remaining bits.
+// For example β=60 gives [60] and β=128 gives [60, 60, 8].
+func (opts *BatchOptions) roundsBits() ([]int, error) {
+	securityLevel, err := opts.securityLevel()
+	if err != nil {
+		return nil, err
+	}
+	return batch.SplitRounds(securityLevel, boundBits), nil
+}
+
+// securityLevel returns the security level β of opts, or an error if it is
+// invalid.
+func (opts *BatchOptions) securityLevel() (int, error) {
+	return batch.SecurityLevel(opts.SecurityLevel, DefaultSecurityLevel)
+}
+
+// randomSources returns n sources of random bytes, one per chunk of a random linear combination,
+// drawn from opts.Rand as batch.RandomSources does.
+func (opts *BatchOptions) randomSources(n int) ([]io.Reader, error) {
+	return batch.RandomSources(opts.Rand, n)
+}
+
+// fiatShamirPrefix, fiatShamirPrefixG2 and fiatShamirPrefixGT separate the
+// transcripts of this package from the ones of the other curves and groups.
+const (
+	fiatShamirPrefix   = "batch-subgroup-membership/BLS12-377-STRONG/G1"
+	fiatShamirPrefixG2 = "batch-subgroup-membership/BLS12-377-STRONG/G2"
+	fiatShamirPrefixGT = "batch-subgroup-membership/BLS12-377-STRONG/GT"
+)
+
+// bindPoints returns opts unchanged, except in the Fiat–Shamir mode where Rand
+// is replaced by the stream of batch.Transcript over the uncompressed
+// encodings of the points, which bind both coordinates, also for points that
+// are not on the curve.
+func (opts BatchOptions) bindPoints(points []G1Affine) BatchOptions {
+	return opts.bindTranscript(fiatShamirPrefix, len(points), func(h hash.Hash, i int) {
+		b := points[i].RawBytes()
+		h.Write(b[:])
+	})
+}
+
+// bindPointsG2 is like bindPoints for points of G2.
+func (opts BatchOptions) bindPointsG2(points []G2Affine) BatchOptions {
+	return opts.bindTranscript(fiatShamirPrefixG2, len(points), func(h hash.Hash, i int) {
+		b := points[i].RawBytes()
+		h.Write(b[:])
+	})
+}
+
+// bindPointsGT is like bindPoints for elements of GT.
+func (opts BatchOptions) bindPointsGT(elts []GT) BatchOptions {
+	return opts.bindTranscript(fiatShamirPrefixGT, len(elts), func(h hash.Hash, i int) {
+		b := elts[i].Bytes()
+		h.Write(b[:])
+	})
+}
+
+// bindTranscript implements bindPoints for n points, whose raw encodings are
+// written by writePoint.
+func (opts BatchOptions) bindTranscript(prefix string, n int, writePoint func(h hash.Hash, i int)) BatchOptions {
+	if len(opts.DomainTag) == 0 {
+		return opts
+	}
+	securityLevel, _ := opts.securityLevel()
+	opts.Rand = batch.Transcript(prefix, opts.DomainTag, securityLevel, n, writePoint)
+	return opts
+}
@@ -0,0 +1,144 @@
+package bls12377strong
+
+import (
+	"context"
+	"io"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/bls12377-strong/fp"
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// IsInSubGroupBatchAggregated is like IsInSubGroupBatch but aggregates the
+// Tate pairings test of the whole batch instead of running it point by point.
+//
+// The Tate pairings of order 2 and 3 of a point are the characters
+// χ₂(x+1), χ₂(x+ω) and χ₃(y-1), where χ₂ is the Legendre symbol and χ₃ the
+// cubic residue symbol. They are multiplicative, so each round checks that
+//
+//	χ₂(∏(x_i+1)^{a_i}·(x_i+ω)^{b_i}) == 1 and χ₃(∏(y_i-1)^{c_i}) == 1
+//
+// for random a_i, b_i ∈ {0,1} and c_i ∈ {0,1,2}. The products are
+// accumulated in a single pass over the points, in which every point is
+// multiplied into the β products of order 2 and the ⌈β/log₂(3)⌉ products of
+// order 3, so that the test costs O(N·β) multiplications and O(β) symbols
+// instead of 3N symbols. If a point fails
+// the Tate pairings test, the exponent of one of its non-trivial characters
+// takes at most one value out of 2, resp. 3, for which the product is a
+// residue, so a round misses it with probability at most 1/2, resp. 1/3. With
+// β rounds of order 2 and ⌈β/log₂(3)⌉ rounds of order 3 such a batch passes
+// with probability at most 2⁻ᵝ. The exponents and the scalars of the random
+// linear combinations are independent, so a batch that contains a point not
+// in G1 is still accepted with probability at most 2⁻ᵝ.
+//
+// A zero factor x+1, x+ω or y-1 is rejected directly, as by checkPoint: the
+// points (-1,0) and (-ω,0) of order 2 and (0,1) of order 3 are not in G1.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+func IsInSubGroupBatchAggregated(points []G1Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return false, err
+	}
+	rounds2, rounds3, err := opts.tateRounds()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	for i := range points {
+		if !points[i].IsInfinity() && !points[i].IsOnCurve() {
+			return false, nil
+		}
+	}
+
+	// 2. Check points are on E[r*e'] with aggregated Tate pairings
+	opts = opts.bindPoints(points)
+	sources, err := opts.randomSources(1)
+	if err != nil {
+		return false, err
+	}
+	if !isTateOneAggregated(points, rounds2, rounds3, sources[0]) {
+		return false, nil
+	}
+
+	// 3. Check Sj are on E[r]
+	return msmCheckRounds(context.Background(), points, roundsBits, &opts)
+}
+
+// tateRounds returns the number of rounds of the aggregated Tate pairings test
+// for the characters of order 2 and 3, see IsInSubGroupBatchAggregated.
+func (opts *BatchOptions) tateRounds() (rounds2, rounds3 int, err error) {
+	securityLevel, err := opts.securityLevel()
+	if err != nil {
+		return 0, 0, err
+	}
+	rounds2, rounds3 = batch.TateRounds(securityLevel)
+	return rounds2, rounds3, nil
+}
+
+// isTateOneAggregated checks that all the points pass the Tate pairings test
+// of checkPoint, except with probability 2⁻ᵝ, using rounds2 and rounds3 random
+// products drawn from rng. The points must be on the curve.
+func isTateOneAggregated(points []G1Affine, rounds2, rounds3 int, rng io.Reader) bool {
+	// running products, one per round
+	acc2 := make([]fp.Element, rounds2)
+	acc3 := make([]fp.Element, rounds3)
+	for i := range acc2 {
+		acc2[i].SetOne()
+	}
+	for i := range acc3 {
+		acc3[i].SetOne()
+	}
+
+	exps := batch.NewExponentReader(rng)
+	var one, tate1, tate2, tate3, tate3Sq fp.Element
+	one.SetOne()
+	for i := range points {
+		// the point at infinity is in G1.
+		if points[i].IsInfinity() {
+			continue
+		}
+		tate1.Add(&points[i].X, &one)
+		tate2.Add(&points[i].X, &thirdRootOneG1)
+		if tate1.IsZero() || tate2.IsZero() {
+			return false
+		}
+
+		for j := range acc2 {
+			e := exps.Bits2()
+			if e&1 != 0 {
+				acc2[j].Mul(&acc2[j], &tate1)
+			}
+			if e&2 != 0 {
+				acc2[j].Mul(&acc2[j], &tate2)
+			}
+		}
+		// y-1=0 would leave the products unchanged, as CubicSymbolFast(0)
+		// is 0, but (0,1) has order 3.
+		tate3.Sub(&points[i].Y, &one)
+		if tate3.IsZero() {
+			return false
+		}
+		tate3Sq.Square(&tate3)
+		for j := range acc3 {
+			switch exps.Trit() {
+			case 1:
+				acc3[j].Mul(&acc3[j], &tate3)
+			case 2:
+				acc3[j].Mul(&acc3[j], &tate3Sq)
+			}
+		}
+	}
+
+	for j := range acc2 {
+		if acc2[j].Legendre() != 1 {
+			return false
+		}
+	}
+	for j := range acc3 {
+		if !IsCubicResidueFast(&acc3[j]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+package bls12377strong
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync/atomic"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/parallel"
+)
+
+// Let h2 be the cofactor of (E'/𝔽p²), the twist that carries G2.
+// h2 = 6340799647724926153732500587885039927452831193774216791577958330188889911509167268465185255953394412556834287188422282928181957717459320718580845000933
+// is a 501-bit prime (see sage/bls12-377-strong.sage), so a point of E'(𝔽p²) that is not
+// in G2 has a non-trivial component of order h2. The random scalars of the
+// linear combination are drawn uniformly in [0, 2^β) with β ≤ MaxSecurityLevel
+// < 501, so they are distinct modulo h2 and such a point survives the
+// combination with probability at most 2⁻ᵝ. Hence, unlike G1, a single
+// combination reaches the security level and no Tate pairings test is needed.
+
+// IsInSubGroupBatchNaiveG2 checks if a batch of points Q_i are in G2.
+// This is a naive method that checks each point individually using Scott test
+// [Scott21].
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func IsInSubGroupBatchNaiveG2(points []G2Affine) bool {
+	for i := range points {
+		if !points[i].IsInSubGroup() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsInSubGroupBatchG2 checks if a batch of points Q_i are in G2.
+// First, it checks that all points are on the curve.
+// Second, it generates random scalars s_i in the range [0, 2^β[, performs one
+// multi-scalar-multiplication S=∑[s_i]Q_i of size N=len(points) and checks if
+// S is in G2 using Scott test [Scott21], where β is the security level of opts.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func IsInSubGroupBatchG2(points []G2Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchG2Context(context.Background(), points, opts)
+}
+
+// IsInSubGroupBatchG2Context is like IsInSubGroupBatchG2 but gives up when ctx
+// is done: the on-curve loop and the multi-scalar-multiplication stop promptly
+// and ctx.Err() is returned.
+func IsInSubGroupBatchG2Context(ctx context.Context, points []G2Affine, opts BatchOptions) (bool, error) {
+	nbBits, err := opts.securityLevel()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	for i := range points {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if !points[i].IsOnCurve() {
+			return false, nil
+		}
+	}
+
+	// 2. Check S is in G2
+	opts = opts.bindPointsG2(points)
+	return msmCheckG2(ctx, points, nbBits, &opts)
+}
+
+func IsInSubGroupBatchG2Parallel(points []G2Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchG2ParallelContext(context.Background(), points, opts)
+}
+
+// IsInSubGroupBatchG2ParallelContext is like IsInSubGroupBatchG2Parallel but
+// gives up when ctx is done, see IsInSubGroupBatchG2Context.
+func IsInSubGroupBatchG2ParallelContext(ctx context.Context, points []G2Affine, opts BatchOptions) (bool, error) {
+	nbBits, err := opts.securityLevel()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	var nbErrors int64
+	err = parallel.ExecuteContext(ctx, len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			if !points[i].IsOnCurve() {
+				atomic.AddInt64(&nbErrors, 1)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+	if nbErrors > 0 {
+		return false, nil
+	}
+
+	// 2. Check S is in G2
+	opts = opts.bindPointsG2(points)
+	return msmCheckG2(ctx, points, nbBits, &opts)
+}
+
+// msmCheckG2 checks that S=∑[s_i]Q_i is in G2 for random scalars s_i in
+// [0, 2^nbBits) drawn from opts.Rand. It returns ctx.Err() if ctx is done
+// before S is computed.
+func msmCheckG2(ctx context.Context, points []G2Affine, nbBits int, opts *BatchOptions) (bool, error) {
+	sources, err := opts.randomSources(msmNbChunks(nbBits))
+	if err != nil {
+		return false, err
+	}
+	var p G2Jac
+	msmRandomCombinationG2(ctx, &p, points, nbBits, sources)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return p.IsInSubGroup(), nil
+}
+
+// msmRandomCombinationG2 sets p to ∑[s_i]Q_i for random scalars s_i in
+// [0, 2^nbBits) and returns p. The digits of the chunk j are read from
+// sources[j], with len(sources) == msmNbChunks(nbBits). If ctx is done the
+// chunks stop early and p is meaningless.
+func msmRandomCombinationG2(ctx context.Context, p *G2Jac, points []G2Affine, nbBits int, sources []io.Reader) *G2Jac {
+	const c = msmC
+	nbChunks := msmNbChunks(nbBits)
+
+	// for each chunk, spawn one go routine that'll loop through all the scalars in the
+	// corresponding bit-window
+	// note that buckets is an array allocated on the stack and this is critical for performance
+
+	// each go routine sends its result in chChunks[i] channel
+	chChunks := make([]chan g2JacExtended, nbChunks)
+	for i := 0; i < len(chChunks); i++ {
+		chChunks[i] = make(chan g2JacExtended, 1)
+	}
+
+	for j := nbChunks - 1; j >= 0; j-- {
+		// the most significant digit may be shorter
+		digitBits := min(c-1, nbBits-j*(c-1))
+		go processChunkG2Simplified[bucketg2JacExtendedC6](ctx, uint64(j), chChunks[j], uint64(digitBits), points, sources[j])
+	}
+
+	return msmReduceChunkG2Affine(p, c-1, chChunks[:])
+}
+
+// processChunkG2Simplified computes ∑[d_i]Q_i for random digits d_i in
+// [0, 2^digitBits) read from rng, with digitBits ≤ 5, using the buckets
+// method. It stops early if ctx is done.
+func processChunkG2Simplified[B bucketg2JacExtendedC6](ctx context.Context, chunk uint64,
+	chRes chan<- g2JacExtended,
+	digitBits uint64,
+	points []G2Affine,
+	rng io.Reader) {
+
+	const windowSize = 1024
+	var br [windowSize * 2]byte
+
+	// we need a mask to get only the digitBits lowest bits of each scalar
+	mask := uint16((1 << digitBits) - 1)
+
+	var buckets B
+	for i := 0; i < len(buckets); i++ {
+		buckets[i].SetInfinity()
+	}
+
+	// for each scalars, get the digit corresponding to the chunk we're processing.
+	for i := range points {
+		if i%windowSize == 0 {
+			if ctx.Err() != nil {
+				break
+			}
+			// fill the lowest c bits of each scalar with random bytes
+			rng.Read(br[:]) // crypto/rand and ChaCha8 do not return an error, always fill br
+		}
+		// br is read as little-endian uint16 so that the digits drawn from a
+		// seeded source do not depend on the platform
+		digit := binary.LittleEndian.Uint16(br[2*(i%windowSize):]) & mask
+		if digit == 0 {
+			continue
+		}
+		buckets[digit-1].addMixed(&points[i])
+	}
+
+	chRes <- reduceBucketsG2(buckets[:])
+}
+
+// reduceBucketsG2 returns the weighted sum of the buckets
+// total =  bucket[0] + 2*bucket[1] + 3*bucket[2] ... + n*bucket[n-1]
+func reduceBucketsG2(buckets []g2JacExtended) g2JacExtended {
+	var runningSum, total g2JacExtended
+	runningSum.SetInfinity()
+	total.SetInfinity()
+	for k := len(buckets) - 1; k >= 0; k-- {
+		if !buckets[k].IsInfinity() {
+			runningSum.add(&buckets[k])
+		}
+		total.add(&runningSum)
+	}
+	return total
+}
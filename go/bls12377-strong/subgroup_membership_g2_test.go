@@ -0,0 +1,199 @@
+package bls12377strong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/bls12377-strong/fr"
+	"github.com/yelhousni/batch-subgroup-membership/go/bls12377-strong/internal/fptower"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestIsInSubGroupBatchG2(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = 1
+	} else {
+		parameters.MinSuccessfulTests = 20
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	// number of points to test
+	const nbSamples = 100
+
+	// random points in G2
+	sample := func(mixer fr.Element) []G2Affine {
+		// mixer ensures that all the words of a frElement are set
+		var sampleScalars [nbSamples]fr.Element
+		for i := 1; i <= nbSamples; i++ {
+			sampleScalars[i-1].SetUint64(uint64(i)).
+				Mul(&sampleScalars[i-1], &mixer)
+		}
+		return BatchScalarMultiplicationG2(&g2GenAff, sampleScalars[:])
+	}
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchG2 test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			result := sample(mixer)
+			ok, err := IsInSubGroupBatchG2(result, batchOptions)
+			return err == nil && ok && IsInSubGroupBatchNaiveG2(result)
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchG2Parallel test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			result := sample(mixer)
+			ok, err := IsInSubGroupBatchG2Parallel(result, batchOptions)
+			return err == nil && ok
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchG2 test should not pass", prop.ForAll(
+		func(mixer fr.Element, a fptower.E2) bool {
+			result := sample(mixer)
+			// a random point of E'(𝔽p²), not in G2
+			result[nbSamples/2] = MapToCurve2(&a)
+
+			ok, err := IsInSubGroupBatchG2(result, batchOptions)
+			okParallel, errParallel := IsInSubGroupBatchG2Parallel(result, batchOptions)
+			return err == nil && !ok && errParallel == nil && !okParallel && !IsInSubGroupBatchNaiveG2(result)
+		},
+		GenFr(),
+		GenE2(),
+	))
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchG2 test should not pass for points not on the curve", prop.ForAll(
+		func(mixer fr.Element, a fptower.E2) bool {
+			result := sample(mixer)
+			result[0].Y.Mul(&result[0].Y, &a)
+			if result[0].IsOnCurve() {
+				return true
+			}
+
+			ok, err := IsInSubGroupBatchG2(result, batchOptions)
+			okParallel, errParallel := IsInSubGroupBatchG2Parallel(result, batchOptions)
+			return err == nil && !ok && errParallel == nil && !okParallel
+		},
+		GenFr(),
+		GenE2(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestIsInSubGroupBatchG2Options(t *testing.T) {
+	t.Parallel()
+
+	points := []G2Affine{g2GenAff, {}, g2GenAff}
+	for _, opts := range []BatchOptions{
+		{SecurityLevel: 1},
+		{SecurityLevel: MaxSecurityLevel},
+		{DomainTag: []byte("test")},
+	} {
+		if ok, err := IsInSubGroupBatchG2(points, opts); err != nil || !ok {
+			t.Fatalf("%+v: expected points in G2, got %v, %v", opts, ok, err)
+		}
+	}
+	if ok, err := IsInSubGroupBatchG2(nil, batchOptions); err != nil || !ok {
+		t.Fatalf("expected no point in G2, got %v, %v", ok, err)
+	}
+
+	// the transcripts of G1 and G2 are separated
+	var s1, s2 [32]byte
+	opts := BatchOptions{DomainTag: []byte("test")}
+	opts.bindPoints(nil).Rand.Read(s1[:])
+	opts.bindPointsG2(nil).Rand.Read(s2[:])
+	if s1 == s2 {
+		t.Fatal("G1 and G2 should have different transcripts")
+	}
+
+	for _, securityLevel := range []int{-1, MaxSecurityLevel + 1} {
+		opts := BatchOptions{SecurityLevel: securityLevel}
+		if _, err := IsInSubGroupBatchG2(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+		if _, err := IsInSubGroupBatchG2Parallel(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+	}
+	if _, err := IsInSubGroupBatchG2(points, BatchOptions{Rand: failingReader{}}); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+}
+
+func TestIsInSubGroupBatchG2Context(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, g := Generators()
+	points := make([]G2Affine, 1<<12)
+	for i := range points {
+		points[i] = g
+	}
+	checks := []func(context.Context, []G2Affine, BatchOptions) (bool, error){
+		IsInSubGroupBatchG2Context,
+		IsInSubGroupBatchG2ParallelContext,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, check := range checks {
+		if ok, err := check(ctx, points, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G2, got %v, %v", ok, err)
+		}
+	}
+
+	// a cancelled context is reported
+	cancel()
+	for _, check := range checks {
+		if _, err := check(ctx, points, batchOptions); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+
+	// the multi-scalar-multiplication goroutines stop too
+	if _, err := msmCheckG2(ctx, points, DefaultSecurityLevel, &batchOptions); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// a deadline in the middle of the checks is honoured
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := IsInSubGroupBatchG2Context(ctx, points, batchOptions); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func BenchmarkIsInSubGroupBatchG2(b *testing.B) {
+	const (
+		pow       = 14
+		nbSamples = 1 << pow
+	)
+	var sampleScalars [nbSamples]fr.Element
+	fillBenchScalars(sampleScalars[:])
+
+	result := BatchScalarMultiplicationG2(&g2GenAff, sampleScalars[:])
+
+	for i := 5; i <= pow; i += 3 {
+		using := 1 << i
+		b.Run(fmt.Sprintf("%d points-naive", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchNaiveG2(result[:using])
+			}
+		})
+		b.Run(fmt.Sprintf("%d points", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchG2(result[:using], batchOptions)
+			}
+		})
+	}
+}
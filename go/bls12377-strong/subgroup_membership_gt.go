@@ -0,0 +1,231 @@
+package bls12377strong
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync/atomic"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/parallel"
+)
+
+// Let ht = (p⁴-p²+1)/r be the cofactor of GT in the cyclotomic subgroup
+// G_{Φ₁₂(p)} of 𝔽p¹²^*. ht is a 1254-bit prime (see sage/bls12-377-strong.sage),
+// so an element of G_{Φ₁₂(p)} that is not in GT has a non-trivial component of
+// order ht. The random exponents of the product are drawn uniformly in
+// [0, 2^β) with β ≤ MaxSecurityLevel < 1254, so they are distinct modulo ht and
+// such an element survives the product with probability at most 2⁻ᵝ. Hence a
+// single product reaches the security level.
+//
+// This does not hold for BLS12-376-strong, whose ht is not prime.
+
+// IsInSubGroupBatchNaiveGT checks if a batch of elements x_i are in GT.
+// This is a naive method that checks each element individually using Scott
+// test [Scott21].
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func IsInSubGroupBatchNaiveGT(elts []GT) bool {
+	for i := range elts {
+		if !isInCyclotomicSubGroup(&elts[i]) || !elts[i].IsInSubGroup() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsInSubGroupBatchGT checks if a batch of elements x_i are in GT.
+// First, it checks that all elements are in the cyclotomic subgroup
+// G_{Φ₁₂(p)}.
+// Second, it generates random exponents s_i in the range [0, 2^β[, performs
+// one multi-exponentiation S=∏x_i^s_i of size N=len(elts) and checks if S is
+// in GT using Scott test [Scott21], where β is the security level of opts.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func IsInSubGroupBatchGT(elts []GT, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchGTContext(context.Background(), elts, opts)
+}
+
+// IsInSubGroupBatchGTContext is like IsInSubGroupBatchGT but gives up when ctx
+// is done: the cyclotomic subgroup loop and the multi-exponentiation stop
+// promptly and ctx.Err() is returned.
+func IsInSubGroupBatchGTContext(ctx context.Context, elts []GT, opts BatchOptions) (bool, error) {
+	nbBits, err := opts.securityLevel()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check elements are in G_{Φ₁₂(p)}
+	for i := range elts {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if !isInCyclotomicSubGroup(&elts[i]) {
+			return false, nil
+		}
+	}
+
+	// 2. Check S is in GT
+	opts = opts.bindPointsGT(elts)
+	return multiExpCheckGT(ctx, elts, nbBits, &opts)
+}
+
+func IsInSubGroupBatchGTParallel(elts []GT, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchGTParallelContext(context.Background(), elts, opts)
+}
+
+// IsInSubGroupBatchGTParallelContext is like IsInSubGroupBatchGTParallel but
+// gives up when ctx is done, see IsInSubGroupBatchGTContext.
+func IsInSubGroupBatchGTParallelContext(ctx context.Context, elts []GT, opts BatchOptions) (bool, error) {
+	nbBits, err := opts.securityLevel()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check elements are in G_{Φ₁₂(p)}
+	var nbErrors int64
+	err = parallel.ExecuteContext(ctx, len(elts), func(start, end int) {
+		for i := start; i < end; i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			if !isInCyclotomicSubGroup(&elts[i]) {
+				atomic.AddInt64(&nbErrors, 1)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+	if nbErrors > 0 {
+		return false, nil
+	}
+
+	// 2. Check S is in GT
+	opts = opts.bindPointsGT(elts)
+	return multiExpCheckGT(ctx, elts, nbBits, &opts)
+}
+
+// isInCyclotomicSubGroup checks that x is in G_{Φ₁₂(p)}, i.e. that x ≠ 0 and
+// x^(p⁴-p²+1) == 1.
+//
+// GT.IsInSubGroup assumes x ≠ 0: 0 passes both of its equations.
+func isInCyclotomicSubGroup(x *GT) bool {
+	if x.IsZero() {
+		return false
+	}
+	var a, b GT
+	// x^(p⁴+1) == x^(p²)
+	a.FrobeniusSquare(x)
+	b.FrobeniusSquare(&a).Mul(&b, x)
+	return a.Equal(&b)
+}
+
+// multiExpCheckGT checks that S=∏x_i^s_i is in GT for random exponents s_i
+// in [0, 2^nbBits) drawn from opts.Rand. The x_i must be in G_{Φ₁₂(p)}.
+// It returns ctx.Err() if ctx is done before S is computed.
+func multiExpCheckGT(ctx context.Context, elts []GT, nbBits int, opts *BatchOptions) (bool, error) {
+	sources, err := opts.randomSources(msmNbChunks(nbBits))
+	if err != nil {
+		return false, err
+	}
+	var s GT
+	multiExpRandomProductGT(ctx, &s, elts, nbBits, sources)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return s.IsInSubGroup(), nil
+}
+
+// multiExpRandomProductGT sets z to ∏x_i^s_i for random exponents s_i in
+// [0, 2^nbBits) and returns z. The digits of the chunk j are read from
+// sources[j], with len(sources) == msmNbChunks(nbBits). The x_i must be in
+// G_{Φ₁₂(p)}, so that the squarings can be cyclotomic. If ctx is done the
+// chunks stop early and z is meaningless.
+func multiExpRandomProductGT(ctx context.Context, z *GT, elts []GT, nbBits int, sources []io.Reader) *GT {
+	const c = msmC
+	nbChunks := msmNbChunks(nbBits)
+
+	// for each chunk, spawn one go routine that'll loop through all the exponents
+	// in the corresponding bit-window, and send its result in chChunks[i]
+	chChunks := make([]chan GT, nbChunks)
+	for i := 0; i < len(chChunks); i++ {
+		chChunks[i] = make(chan GT, 1)
+	}
+
+	for j := nbChunks - 1; j >= 0; j-- {
+		// the most significant digit may be shorter
+		digitBits := min(c-1, nbBits-j*(c-1))
+		go processChunkGT(ctx, chChunks[j], uint64(digitBits), elts, sources[j])
+	}
+
+	// z = ∏ totalⱼ^(2^(j·(c-1)))
+	*z = <-chChunks[nbChunks-1]
+	for j := nbChunks - 2; j >= 0; j-- {
+		for l := 0; l < c-1; l++ {
+			z.CyclotomicSquare(z)
+		}
+		totalj := <-chChunks[j]
+		z.Mul(z, &totalj)
+	}
+	return z
+}
+
+// processChunkGT computes ∏x_i^d_i for random digits d_i in [0, 2^digitBits)
+// read from rng, with digitBits ≤ 5, using the buckets method. It stops early
+// if ctx is done.
+func processChunkGT(ctx context.Context, chRes chan<- GT,
+	digitBits uint64,
+	elts []GT,
+	rng io.Reader) {
+
+	const windowSize = 1024
+	var br [windowSize * 2]byte
+
+	// we need a mask to get only the digitBits lowest bits of each exponent
+	mask := uint16((1 << digitBits) - 1)
+
+	// an empty bucket is 0, which is not in G_{Φ₁₂(p)}
+	var buckets [(1 << (msmC - 1)) - 1]GT
+
+	// for each exponent, get the digit corresponding to the chunk we're processing.
+	for i := range elts {
+		if i%windowSize == 0 {
+			if ctx.Err() != nil {
+				break
+			}
+			// fill the lowest c bits of each exponent with random bytes
+			rng.Read(br[:]) // crypto/rand and ChaCha8 do not return an error, always fill br
+		}
+		// br is read as little-endian uint16 so that the digits drawn from a
+		// seeded source do not depend on the platform
+		digit := binary.LittleEndian.Uint16(br[2*(i%windowSize):]) & mask
+		if digit == 0 {
+			continue
+		}
+		if buckets[digit-1].IsZero() {
+			buckets[digit-1].Set(&elts[i])
+		} else {
+			buckets[digit-1].Mul(&buckets[digit-1], &elts[i])
+		}
+	}
+
+	chRes <- reduceBucketsGT(buckets[:])
+}
+
+// reduceBucketsGT returns the weighted product of the buckets
+// total = bucket[0] * bucket[1]^2 * bucket[2]^3 ... * bucket[n-1]^n
+func reduceBucketsGT(buckets []GT) GT {
+	var runningProduct, total GT
+	runningProduct.SetOne()
+	total.SetOne()
+	for k := len(buckets) - 1; k >= 0; k-- {
+		if !buckets[k].IsZero() {
+			runningProduct.Mul(&runningProduct, &buckets[k])
+		}
+		total.Mul(&total, &runningProduct)
+	}
+	return total
+}
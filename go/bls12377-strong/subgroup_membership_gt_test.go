@@ -0,0 +1,221 @@
+package bls12377strong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/bls12377-strong/fr"
+	"github.com/yelhousni/batch-subgroup-membership/go/bls12377-strong/internal/fptower"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestIsInSubGroupBatchGT(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = 1
+	} else {
+		parameters.MinSuccessfulTests = 10
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	// number of elements to test
+	const nbSamples = 50
+
+	// random elements in GT
+	sample := func(mixer fr.Element) []GT {
+		return sampleGT(mixer, nbSamples)
+	}
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchGT test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			result := sample(mixer)
+			ok, err := IsInSubGroupBatchGT(result, batchOptions)
+			return err == nil && ok && IsInSubGroupBatchNaiveGT(result)
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchGTParallel test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			result := sample(mixer)
+			ok, err := IsInSubGroupBatchGTParallel(result, batchOptions)
+			return err == nil && ok
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchGT test should not pass", prop.ForAll(
+		func(mixer fr.Element, a GT) bool {
+			result := sample(mixer)
+			// a random element of G_{Φ₁₂(p)}, not in GT
+			result[nbSamples/2] = fuzzCyclotomic(a)
+
+			ok, err := IsInSubGroupBatchGT(result, batchOptions)
+			okParallel, errParallel := IsInSubGroupBatchGTParallel(result, batchOptions)
+			return err == nil && !ok && errParallel == nil && !okParallel && !IsInSubGroupBatchNaiveGT(result)
+		},
+		GenFr(),
+		GenE12(),
+	))
+
+	properties.Property("[BLS12-377-STRONG] IsInSubGroupBatchGT test should not pass for elements not in the cyclotomic subgroup", prop.ForAll(
+		func(mixer fr.Element, a GT) bool {
+			result := sample(mixer)
+			result[0] = a
+			if isInCyclotomicSubGroup(&result[0]) {
+				return true
+			}
+
+			ok, err := IsInSubGroupBatchGT(result, batchOptions)
+			okParallel, errParallel := IsInSubGroupBatchGTParallel(result, batchOptions)
+			return err == nil && !ok && errParallel == nil && !okParallel
+		},
+		GenFr(),
+		GenE12(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestIsInSubGroupBatchGTOptions(t *testing.T) {
+	t.Parallel()
+
+	var one GT
+	one.SetOne()
+	elts := append(sampleGT(fr.One(), 2), one)
+	for _, opts := range []BatchOptions{
+		{SecurityLevel: 1},
+		{SecurityLevel: MaxSecurityLevel},
+		{DomainTag: []byte("test")},
+	} {
+		if ok, err := IsInSubGroupBatchGT(elts, opts); err != nil || !ok {
+			t.Fatalf("%+v: expected elements in GT, got %v, %v", opts, ok, err)
+		}
+	}
+	if ok, err := IsInSubGroupBatchGT(nil, batchOptions); err != nil || !ok {
+		t.Fatalf("expected no element in GT, got %v, %v", ok, err)
+	}
+
+	// 0 is not in GT, although it passes GT.IsInSubGroup
+	if ok, err := IsInSubGroupBatchGT([]GT{one, {}}, batchOptions); err != nil || ok {
+		t.Fatalf("expected 0 not in GT, got %v, %v", ok, err)
+	}
+
+	// the transcripts of G1 and GT are separated
+	var s1, st [32]byte
+	opts := BatchOptions{DomainTag: []byte("test")}
+	opts.bindPoints(nil).Rand.Read(s1[:])
+	opts.bindPointsGT(nil).Rand.Read(st[:])
+	if s1 == st {
+		t.Fatal("G1 and GT should have different transcripts")
+	}
+
+	for _, securityLevel := range []int{-1, MaxSecurityLevel + 1} {
+		opts := BatchOptions{SecurityLevel: securityLevel}
+		if _, err := IsInSubGroupBatchGT(elts, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+		if _, err := IsInSubGroupBatchGTParallel(elts, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+	}
+	if _, err := IsInSubGroupBatchGT(elts, BatchOptions{Rand: failingReader{}}); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+}
+
+func TestIsInSubGroupBatchGTContext(t *testing.T) {
+	t.Parallel()
+
+	elts := sampleGT(fr.One(), 1<<10)
+	checks := []func(context.Context, []GT, BatchOptions) (bool, error){
+		IsInSubGroupBatchGTContext,
+		IsInSubGroupBatchGTParallelContext,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, check := range checks {
+		if ok, err := check(ctx, elts, batchOptions); err != nil || !ok {
+			t.Fatalf("expected elements in GT, got %v, %v", ok, err)
+		}
+	}
+
+	// a cancelled context is reported
+	cancel()
+	for _, check := range checks {
+		if _, err := check(ctx, elts, batchOptions); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+
+	// the multi-exponentiation goroutines stop too
+	if _, err := multiExpCheckGT(ctx, elts, DefaultSecurityLevel, &batchOptions); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// a deadline in the middle of the checks is honoured
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := IsInSubGroupBatchGTContext(ctx, elts, batchOptions); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func BenchmarkIsInSubGroupBatchGT(b *testing.B) {
+	const (
+		pow       = 11
+		nbSamples = 1 << pow
+	)
+	result := sampleGT(fr.One(), nbSamples)
+
+	for i := 5; i <= pow; i += 3 {
+		using := 1 << i
+		b.Run(fmt.Sprintf("%d elements-naive", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchNaiveGT(result[:using])
+			}
+		})
+		b.Run(fmt.Sprintf("%d elements", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchGT(result[:using], batchOptions)
+			}
+		})
+	}
+}
+
+// sampleGT returns the n elements e(G1, G2)^(i·mixer) of GT, for i in [1, n].
+func sampleGT(mixer fr.Element, n int) []GT {
+	g, _ := Pair([]G1Affine{g1GenAff}, []G2Affine{g2GenAff})
+	var step GT
+	var e big.Int
+	mixer.BigInt(&e)
+	step.CyclotomicExp(g, &e)
+
+	res := make([]GT, n)
+	res[0] = step
+	for i := 1; i < n; i++ {
+		res[i].Mul(&res[i-1], &step)
+	}
+	return res
+}
+
+// fuzzCyclotomic maps a to G_{Φ₁₂(p)} using the easy part of the final
+// exponentiation a^((p⁶-1)(p²+1)).
+func fuzzCyclotomic(a fptower.E12) GT {
+	var t GT
+	t.Conjugate(&a)
+	a.Inverse(&a)
+	t.Mul(&t, &a)
+	a.FrobeniusSquare(&t)
+	return *a.Mul(&a, &t)
+}
@@ -1,12 +1,17 @@
 package bls12377strong
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	mrand "math/rand/v2"
 	"testing"
+	"time"
 
 	"github.com/yelhousni/batch-subgroup-membership/go/bls12377-strong/fp"
 	"github.com/yelhousni/batch-subgroup-membership/go/bls12377-strong/fr"
+	"github.com/yelhousni/batch-subgroup-membership/go/eisenstein"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/prop"
@@ -15,9 +20,10 @@ import (
 // Let h be the cofactor of (E/𝔽p).
 // h = 3 * (2 * 1553806976791259819)²
 // We choose bound 1152921504606846976 = 2^60 < 1553806976791259819.
-// For a failure probability of 2⁻ᵝ we need to set rounds=⌈β⌉.
-// For example β=64 gives rounds=1 and β=128 gives rounds=2.
-var rounds = 1
+// For a failure probability of 2⁻ᵝ we need rounds=⌈β/60⌉, which is what
+// BatchOptions{SecurityLevel: β} selects.
+// For example β=60 gives rounds=1 and β=128 gives rounds=3.
+var batchOptions = BatchOptions{SecurityLevel: DefaultSecurityLevel}
 
 func TestIsInSubGroupBatch(t *testing.T) {
 	t.Parallel()
@@ -90,7 +96,8 @@ func TestIsInSubGroupBatch(t *testing.T) {
 			_, _, g, _ := Generators()
 			result := BatchScalarMultiplicationG1(&g, sampleScalars[:])
 
-			return IsInSubGroupBatch(result, rounds)
+			ok, err := IsInSubGroupBatch(result, batchOptions)
+			return err == nil && ok
 		},
 		GenFr(),
 	))
@@ -115,15 +122,582 @@ func TestIsInSubGroupBatch(t *testing.T) {
 			h = fuzzCofactorOfG1(a)
 			result[nbSamples-1].FromJacobian(&h)
 
-			return !IsInSubGroupBatch(result, rounds)
+			ok, err := IsInSubGroupBatch(result, batchOptions)
+			return err == nil && !ok
 		},
 		GenFr(),
 		GenFp(),
 	))
 
+	properties.Property("[BLS12-377-STRONG] FindNonMembers should return the indices of the points not in G1", prop.ForAll(
+		func(mixer fr.Element, a fp.Element) bool {
+			// mixer ensures that all the words of a frElement are set
+			var sampleScalars [nbSamples]fr.Element
+
+			for i := 1; i <= nbSamples; i++ {
+				sampleScalars[i-1].SetUint64(uint64(i)).
+					Mul(&sampleScalars[i-1], &mixer)
+			}
+
+			// random points in G1
+			_, _, g, _ := Generators()
+			result := BatchScalarMultiplicationG1(&g, sampleScalars[:])
+
+			// random points in the h-torsion
+			h := fuzzCofactorOfG1(a)
+			result[0].FromJacobian(&h)
+			h = fuzzCofactorOfG1(a)
+			result[nbSamples-1].FromJacobian(&h)
+			// random points on E[e'] that pass the Tate pairings test
+			h = fuzzTateOneNotInG1(a)
+			result[nbSamples/2].FromJacobian(&h)
+			h = fuzzTateOneNotInG1(a)
+			result[nbSamples/2+1].FromJacobian(&h)
+
+			var expected []int
+			for i := range result {
+				if !result[i].IsInSubGroup() {
+					expected = append(expected, i)
+				}
+			}
+
+			nonMembers, err := FindNonMembers(result, batchOptions)
+			if err != nil {
+				return false
+			}
+			if len(nonMembers) != len(expected) {
+				return false
+			}
+			for i := range expected {
+				if nonMembers[i] != expected[i] {
+					return false
+				}
+			}
+			return true
+		},
+		GenFr(),
+		GenFp(),
+	))
+
+	properties.Property("[BLS12-377-STRONG] FindNonMembers should return no index for points in G1", prop.ForAll(
+		func(mixer fr.Element) bool {
+			// mixer ensures that all the words of a frElement are set
+			var sampleScalars [nbSamples]fr.Element
+
+			for i := 1; i <= nbSamples; i++ {
+				sampleScalars[i-1].SetUint64(uint64(i)).
+					Mul(&sampleScalars[i-1], &mixer)
+			}
+
+			// random points in G1
+			_, _, g, _ := Generators()
+			result := BatchScalarMultiplicationG1(&g, sampleScalars[:])
+
+			nonMembers, err := FindNonMembers(result, batchOptions)
+			return err == nil && len(nonMembers) == 0
+		},
+		GenFr(),
+	))
+
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestBatchOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		securityLevel int
+		roundsBits    []int
+	}{
+		{0, []int{60}},
+		{1, []int{1}},
+		{60, []int{60}},
+		{64, []int{60, 4}},
+		{128, []int{60, 60, 8}},
+		{MaxSecurityLevel, []int{60, 60, 60, 60, 16}},
+	} {
+		opts := BatchOptions{SecurityLevel: tc.securityLevel}
+		roundsBits, err := opts.roundsBits()
+		if err != nil {
+			t.Fatalf("security level %d: unexpected error: %v", tc.securityLevel, err)
+		}
+		if fmt.Sprint(roundsBits) != fmt.Sprint(tc.roundsBits) {
+			t.Fatalf("security level %d: expected %v, got %v", tc.securityLevel, tc.roundsBits, roundsBits)
+		}
+	}
+
+	_, _, g, _ := Generators()
+	points := []G1Affine{g}
+	for _, securityLevel := range []int{-1, MaxSecurityLevel + 1} {
+		opts := BatchOptions{SecurityLevel: securityLevel}
+		if _, err := IsInSubGroupBatch(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+		if _, err := IsInSubGroupBatchParallel(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+		if _, err := FindNonMembers(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+	}
+}
+
+// failingReader is an io.Reader that always fails.
+type failingReader struct{}
+
+var errFailingReader = errors.New("failing reader")
+
+func (failingReader) Read([]byte) (int, error) { return 0, errFailingReader }
+
+func TestBatchOptionsRand(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := Generators()
+	points := make([]G1Affine, 16)
+	for i := range points {
+		points[i].ScalarMultiplication(&g, big.NewInt(int64(i+1)))
+	}
+
+	// the same seed draws the same random linear combination
+	combination := func(seed [32]byte) G1Jac {
+		opts := BatchOptions{Rand: mrand.NewChaCha8(seed)}
+		sources, err := opts.randomSources(msmNbChunks(boundBits))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p G1Jac
+		msmRandomCombination(context.Background(), &p, points, boundBits, sources)
+		return p
+	}
+	p1, p2, p3 := combination([32]byte{1}), combination([32]byte{1}), combination([32]byte{2})
+	if !p1.Equal(&p2) {
+		t.Fatal("same seed should draw the same random linear combination")
+	}
+	if p1.Equal(&p3) {
+		t.Fatal("different seeds should draw different random linear combinations")
+	}
+
+	// a failing source of randomness is reported
+	opts := BatchOptions{Rand: failingReader{}}
+	if _, err := IsInSubGroupBatch(points, opts); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+	if _, err := IsInSubGroupBatchParallel(points, opts); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+	if _, err := FindNonMembers(points, opts); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+
+	// a seeded source of randomness is accepted
+	opts = BatchOptions{Rand: mrand.NewChaCha8([32]byte{3})}
+	if ok, err := IsInSubGroupBatch(points, opts); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+}
+
+func TestBatchOptionsFiatShamir(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := Generators()
+	points := make([]G1Affine, 16)
+	for i := range points {
+		points[i].ScalarMultiplication(&g, big.NewInt(int64(i+1)))
+	}
+
+	// the transcript seed only depends on the domain tag, the security level
+	// and the points
+	seed := func(opts BatchOptions, points []G1Affine) [32]byte {
+		var res [32]byte
+		if _, err := opts.bindPoints(points).Rand.Read(res[:]); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	opts := BatchOptions{DomainTag: []byte("test")}
+	s := seed(opts, points)
+	if seed(opts, points) != s {
+		t.Fatal("same transcript should give the same seed")
+	}
+	if seed(BatchOptions{DomainTag: []byte("tset")}, points) == s {
+		t.Fatal("different domain tags should give different seeds")
+	}
+	if seed(BatchOptions{DomainTag: []byte("test"), SecurityLevel: 1}, points) == s {
+		t.Fatal("different security levels should give different seeds")
+	}
+	if seed(opts, points[1:]) == s {
+		t.Fatal("different points should give different seeds")
+	}
+	if opts := (BatchOptions{}).bindPoints(points); opts.Rand != nil {
+		t.Fatal("bindPoints should not change opts without a domain tag")
+	}
+
+	// the decision is the same on every run
+	if ok, err := IsInSubGroupBatch(points, opts); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	if ok, err := IsInSubGroupBatchParallel(points, opts); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	var f fp.Element
+	f.SetRandom()
+	q := fuzzTateOneNotInG1(f)
+	points[5].FromJacobian(&q)
+	for range 2 {
+		if ok, err := IsInSubGroupBatch(points, opts); err != nil || ok {
+			t.Fatalf("expected points not in G1, got %v, %v", ok, err)
+		}
+		if ok, err := IsInSubGroupBatchParallel(points, opts); err != nil || ok {
+			t.Fatalf("expected points not in G1, got %v, %v", ok, err)
+		}
+		nonMembers, err := FindNonMembers(points, opts)
+		if err != nil || fmt.Sprint(nonMembers) != "[5]" {
+			t.Fatalf("expected [5], got %v, %v", nonMembers, err)
+		}
+	}
+}
+
+func TestCheckSubGroupBatch(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := Generators()
+
+	// points rejected for a known reason
+	offCurve := g
+	offCurve.Y.Double(&offCurve.Y)
+
+	var torsion2, torsion3, notInG1 G1Affine
+	var found2, found3 bool
+	for !found2 || !found3 {
+		var f fp.Element
+		f.SetRandom()
+		q := fuzzCofactorOfG1(f)
+		var a G1Affine
+		a.FromJacobian(&q)
+		switch {
+		case !isFirstTateOne(a):
+			torsion2, found2 = a, true
+		case !isSecondTateOne(a):
+			torsion3, found3 = a, true
+		}
+	}
+	var f fp.Element
+	f.SetRandom()
+	q := fuzzTateOneNotInG1(f)
+	notInG1.FromJacobian(&q)
+
+	// P3 = (0,1) has order 3 and a zero cubic symbol in the Tate pairings test
+	var p3 G1Affine
+	p3.Y.SetOne()
+
+	cases := []struct {
+		name  string
+		point G1Affine
+		err   error
+		index int
+	}{
+		{"not on the curve", offCurve, ErrNotOnCurve, 3},
+		{"component of order 2", torsion2, ErrTorsion2, 3},
+		{"component of order 3", torsion3, ErrTorsion3, 3},
+		{"point (0,1) of order 3", p3, ErrTorsion3, 3},
+		{"random linear combination", notInG1, ErrRandomCombination, -1},
+	}
+
+	points := make([]G1Affine, 16)
+	for i := range points {
+		points[i] = g
+	}
+	checks := []func([]G1Affine, BatchOptions) error{CheckSubGroupBatch, CheckSubGroupBatchParallel}
+	for _, check := range checks {
+		if err := check(points, batchOptions); err != nil {
+			t.Fatalf("expected points in G1, got %v", err)
+		}
+		if err := check(points, BatchOptions{SecurityLevel: -1}); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("expected ErrInvalidSecurityLevel, got %v", err)
+		}
+	}
+
+	for _, tc := range cases {
+		// the first offending point is reported
+		bad := make([]G1Affine, len(points))
+		copy(bad, points)
+		bad[3], bad[11] = tc.point, tc.point
+		for _, check := range checks {
+			err := check(bad, batchOptions)
+			var batchErr *BatchError
+			if !errors.Is(err, tc.err) || !errors.As(err, &batchErr) || batchErr.Index != tc.index {
+				t.Fatalf("%s: expected %v at index %d, got %v", tc.name, tc.err, tc.index, err)
+			}
+		}
+	}
+}
+
+func TestIsInSubGroupBatchInvalidPoints(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := Generators()
+	points := make([]G1Affine, 16)
+	for i := range points {
+		points[i].ScalarMultiplication(&g, big.NewInt(int64(i+1)))
+	}
+
+	// b = y²-x³ for the generator
+	var b, x3 fp.Element
+	b.Square(&g.Y)
+	x3.Square(&g.X).Mul(&x3, &g.X)
+	b.Sub(&b, &x3)
+
+	// a point on the quadratic twist d·y² = x³+b, with d a non-square
+	var d fp.Element
+	for d.SetRandom(); d.Legendre() != -1; d.SetRandom() {
+	}
+	var twist G1Affine
+	for {
+		var rhs fp.Element
+		twist.X.SetRandom()
+		rhs.Square(&twist.X).Mul(&rhs, &twist.X).Add(&rhs, &b).Div(&rhs, &d)
+		if twist.Y.Sqrt(&rhs) != nil {
+			break
+		}
+	}
+
+	// a point neither on the curve nor on its twist
+	offCurve := g
+	offCurve.Y.Double(&offCurve.Y)
+
+	for _, tc := range []struct {
+		name  string
+		point G1Affine
+	}{
+		{"twist", twist},
+		{"not on the curve", offCurve},
+	} {
+		bad := make([]G1Affine, len(points))
+		copy(bad, points)
+		bad[6] = tc.point
+
+		if ok, err := IsInSubGroupBatch(bad, batchOptions); err != nil || ok {
+			t.Fatalf("%s: expected points not in G1, got %v, %v", tc.name, ok, err)
+		}
+		if ok, err := IsInSubGroupBatchParallel(bad, batchOptions); err != nil || ok {
+			t.Fatalf("%s: expected points not in G1, got %v, %v", tc.name, ok, err)
+		}
+		if nonMembers, err := FindNonMembers(bad, batchOptions); err != nil || fmt.Sprint(nonMembers) != "[6]" {
+			t.Fatalf("%s: expected [6], got %v, %v", tc.name, nonMembers, err)
+		}
+		if err := CheckSubGroupBatch(bad, batchOptions); !errors.Is(err, ErrNotOnCurve) {
+			t.Fatalf("%s: expected ErrNotOnCurve, got %v", tc.name, err)
+		}
+	}
+
+	// the point at infinity is in G1
+	var infinity G1Affine
+	withInfinity := make([]G1Affine, len(points))
+	copy(withInfinity, points)
+	withInfinity[0], withInfinity[9] = infinity, infinity
+	for _, batch := range [][]G1Affine{withInfinity, {infinity}, {infinity, infinity}} {
+		if ok, err := IsInSubGroupBatch(batch, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G1, got %v, %v", ok, err)
+		}
+		if ok, err := IsInSubGroupBatchParallel(batch, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G1, got %v, %v", ok, err)
+		}
+		if nonMembers, err := FindNonMembers(batch, batchOptions); err != nil || len(nonMembers) != 0 {
+			t.Fatalf("expected no index, got %v, %v", nonMembers, err)
+		}
+		if err := CheckSubGroupBatch(batch, batchOptions); err != nil {
+			t.Fatalf("expected points in G1, got %v", err)
+		}
+	}
+
+	// the point P3 = (0,1) of order 3 is not in G1, although the cubic symbol
+	// of y-1 = 0 is trivial
+	var p3 G1Affine
+	p3.Y.SetOne()
+	withP3 := make([]G1Affine, len(points))
+	copy(withP3, points)
+	withP3[6] = p3
+	checks := []func([]G1Affine, BatchOptions) (bool, error){
+		IsInSubGroupBatch,
+		IsInSubGroupBatchParallel,
+		func(points []G1Affine, opts BatchOptions) (bool, error) {
+			return IsInSubGroupBatchContext(context.Background(), points, opts)
+		},
+		func(points []G1Affine, opts BatchOptions) (bool, error) {
+			return IsInSubGroupBatchParallelContext(context.Background(), points, opts)
+		},
+		func(points []G1Affine, opts BatchOptions) (bool, error) {
+			v := NewBatchVerifier(opts)
+			v.AddMany(points)
+			return v.Verify()
+		},
+	}
+	for _, batch := range [][]G1Affine{withP3, {p3}} {
+		for _, check := range checks {
+			if ok, err := check(batch, batchOptions); err != nil || ok {
+				t.Fatalf("(0,1): expected points not in G1, got %v, %v", ok, err)
+			}
+		}
+		if err := CheckSubGroupBatch(batch, batchOptions); !errors.Is(err, ErrTorsion3) {
+			t.Fatalf("(0,1): expected ErrTorsion3, got %v", err)
+		}
+		if err := CheckSubGroupBatchParallel(batch, batchOptions); !errors.Is(err, ErrTorsion3) {
+			t.Fatalf("(0,1): expected ErrTorsion3, got %v", err)
+		}
+	}
+	if nonMembers, err := FindNonMembers(withP3, batchOptions); err != nil || fmt.Sprint(nonMembers) != "[6]" {
+		t.Fatalf("(0,1): expected [6], got %v, %v", nonMembers, err)
+	}
+}
+
+func TestIsInSubGroupBatchContext(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := Generators()
+	points := make([]G1Affine, 1<<12)
+	for i := range points {
+		points[i] = g
+	}
+	checks := []func(context.Context, []G1Affine, BatchOptions) (bool, error){
+		IsInSubGroupBatchContext,
+		IsInSubGroupBatchParallelContext,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, check := range checks {
+		if ok, err := check(ctx, points, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G1, got %v, %v", ok, err)
+		}
+	}
+
+	// a cancelled context is reported
+	cancel()
+	for _, check := range checks {
+		if _, err := check(ctx, points, batchOptions); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+
+	// the multi-scalar-multiplication goroutines stop too
+	sources, err := batchOptions.randomSources(msmNbChunks(boundBits))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := _msmCheck(ctx, points, boundBits, sources); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// a deadline in the middle of the checks is honoured
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := IsInSubGroupBatchContext(ctx, points, batchOptions); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestBatchVerifier(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := Generators()
+	points := make([]G1Affine, 200)
+	for i := range points {
+		points[i].ScalarMultiplication(&g, big.NewInt(int64(i+1)))
+	}
+	var f fp.Element
+	f.SetRandom()
+	q := fuzzTateOneNotInG1(f)
+	var notInG1 G1Affine
+	notInG1.FromJacobian(&q)
+
+	// points streamed one by one and in slices, across several windows
+	stream := func(opts BatchOptions, points []G1Affine) (bool, error) {
+		v := NewBatchVerifier(opts)
+		v.Add(points[0])
+		v.AddMany(points[1:100])
+		for i := 100; i < len(points); i++ {
+			v.Add(points[i])
+		}
+		return v.Verify()
+	}
+	if ok, err := stream(batchOptions, points); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	for _, i := range []int{0, 64, 150, len(points) - 1} {
+		bad := make([]G1Affine, len(points))
+		copy(bad, points)
+		bad[i] = notInG1
+		if ok, err := stream(batchOptions, bad); err != nil || ok {
+			t.Fatalf("point %d: expected points not in G1, got %v, %v", i, ok, err)
+		}
+	}
+
+	// points that fail the Tate pairings test are rejected when added
+	var cofactor G1Affine
+	for {
+		f.SetRandom()
+		q := fuzzCofactorOfG1(f)
+		cofactor.FromJacobian(&q)
+		if checkPoint(&cofactor) != nil {
+			break
+		}
+	}
+	v := NewBatchVerifier(batchOptions)
+	v.AddMany(points[:10])
+	v.Add(cofactor)
+	if !v.rejected {
+		t.Fatal("expected the point to be rejected when added")
+	}
+	v.AddMany(points[10:])
+	if ok, err := v.Verify(); err != nil || ok {
+		t.Fatalf("expected points not in G1, got %v, %v", ok, err)
+	}
+
+	// no point and the point at infinity are in G1
+	v = NewBatchVerifier(batchOptions)
+	if ok, err := v.Verify(); err != nil || !ok {
+		t.Fatalf("expected no point in G1, got %v, %v", ok, err)
+	}
+	v = NewBatchVerifier(batchOptions)
+	v.Add(G1Affine{})
+	if ok, err := v.Verify(); err != nil || !ok {
+		t.Fatalf("expected infinity in G1, got %v, %v", ok, err)
+	}
+
+	// Verify is terminal
+	v = NewBatchVerifier(batchOptions)
+	if err := v.AddMany(points); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := v.Verify(); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	if err := v.Add(notInG1); !errors.Is(err, ErrVerified) {
+		t.Fatalf("expected ErrVerified, got %v", err)
+	}
+	if err := v.AddMany([]G1Affine{notInG1}); !errors.Is(err, ErrVerified) {
+		t.Fatalf("expected ErrVerified, got %v", err)
+	}
+	if _, err := v.Verify(); !errors.Is(err, ErrVerified) {
+		t.Fatalf("expected ErrVerified, got %v", err)
+	}
+
+	// errors due to the options are reported by Verify
+	for _, tc := range []struct {
+		opts BatchOptions
+		err  error
+	}{
+		{BatchOptions{SecurityLevel: -1}, ErrInvalidSecurityLevel},
+		{BatchOptions{DomainTag: []byte("test")}, ErrStreamingDomainTag},
+		{BatchOptions{Rand: failingReader{}}, errFailingReader},
+	} {
+		v := NewBatchVerifier(tc.opts)
+		v.AddMany(points)
+		if _, err := v.Verify(); !errors.Is(err, tc.err) {
+			t.Fatalf("expected %v, got %v", tc.err, err)
+		}
+	}
+}
+
 func TestTatePairings(t *testing.T) {
 	t.Parallel()
 	parameters := gopter.DefaultTestParameters()
@@ -156,6 +730,47 @@ func TestTatePairings(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestTatePairingsSmallTorsion(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := Generators()
+
+	// E(𝔽p)[6] = E[2] ⊕ ⟨P3⟩, with E[2] = {O, (-1,0), (-ω,0), (-ω²,0)} and
+	// P3 = (0,1)
+	var one, omega2 fp.Element
+	one.SetOne()
+	omega2.Square(&thirdRootOneG1)
+	torsion2 := make([]G1Affine, 4)
+	for i, x := range []fp.Element{one, thirdRootOneG1, omega2} {
+		torsion2[i+1].X.Neg(&x)
+	}
+	torsion3 := make([]G1Affine, 3)
+	torsion3[1].Y.SetOne()
+	torsion3[2].Neg(&torsion3[1])
+
+	// every point with a non-trivial component of order 2 or 3 fails the Tate
+	// pairings test, alone or added to a point of G1, so that a point that
+	// passes it and is not in G1 has a non-trivial component of order ℓ.
+	for i := range torsion2 {
+		for j := range torsion3 {
+			var q, r G1Affine
+			q.Add(&torsion2[i], &torsion3[j])
+			if q.IsInfinity() {
+				continue
+			}
+			if !q.IsOnCurve() {
+				t.Fatalf("%s should be on the curve", q.String())
+			}
+			r.Add(&q, &g)
+			for _, p := range []G1Affine{q, r} {
+				if checkPoint(&p) == nil {
+					t.Fatalf("%s should fail the Tate pairings test", p.String())
+				}
+			}
+		}
+	}
+}
+
 func TestElementCubicSymbol(t *testing.T) {
 	t.Parallel()
 	parameters := gopter.DefaultTestParameters()
@@ -177,10 +792,145 @@ func TestElementCubicSymbol(t *testing.T) {
 		GenFp(),
 	))
 
+	properties.Property("CubicSymbolBatch should output same result as CubicSymbolFast", prop.ForAll(
+		func(a fp.Element) bool {
+			// a, a², …, and the edge cases 0, 1, -1
+			xs := make(fp.Vector, 64)
+			xs[0] = a
+			for i := 1; i < len(xs)-3; i++ {
+				xs[i].Mul(&xs[i-1], &a)
+			}
+			xs[len(xs)-2].SetOne()
+			xs[len(xs)-1].SetOne().Neg(&xs[len(xs)-1])
+
+			dst := make([]uint8, len(xs))
+			CubicSymbolBatch(dst, xs)
+			for i := range xs {
+				if dst[i] != CubicSymbolFast(xs[i]) {
+					return false
+				}
+			}
+			return true
+		},
+		GenFp(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestCubicSymbolPrime(t *testing.T) {
+	t.Parallel()
+
+	expected, err := eisenstein.PrimeAbove(fp.Modulus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cubicCtx.Prime().Equal(expected) {
+		t.Fatalf("expected %v, got %v", expected, cubicCtx.Prime())
+	}
+}
+
+func TestElementResidueSymbolsConstantTime(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = 1
+	} else {
+		parameters.MinSuccessfulTests = 100
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("CubicSymbolConstantTime should output same result as CubicSymbolFast and eisenstein.CubicResidueSymbol", prop.ForAll(
+		func(a fp.Element) bool {
+			sym := CubicSymbolConstantTime(a)
+			return sym == CubicSymbolFast(a) && (a.IsZero() || int(sym) == cubicResidueSymbol(t, a))
+		},
+		GenFp(),
+	))
+
+	properties.Property("CubicSymbolConstantTime should be multiplicative", prop.ForAll(
+		func(a, b fp.Element) bool {
+			var ab fp.Element
+			ab.Mul(&a, &b)
+			return CubicSymbolConstantTime(ab) == (CubicSymbolConstantTime(a)+CubicSymbolConstantTime(b))%3
+		},
+		GenFp(),
+		GenFp(),
+	))
+
+	properties.Property("LegendreConstantTime should output same result as Legendre", prop.ForAll(
+		func(a fp.Element) bool {
+			return LegendreConstantTime(a) == a.Legendre()
+		},
+		GenFp(),
+	))
+
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	var zero, one fp.Element
+	one.SetOne()
+	if CubicSymbolConstantTime(zero) != 0 || CubicSymbolFast(zero) != 0 || LegendreConstantTime(zero) != 0 {
+		t.Fatal("expected 0 for the symbols of 0")
+	}
+	// 1 and ω
+	for _, tc := range []struct {
+		x      fp.Element
+		a0, a1 int64 // x ≡ a0 + a1·ω mod β
+	}{
+		{one, 1, 0},
+		{cubOmega, 0, 1},
+	} {
+		var alpha eisenstein.ComplexNumber
+		alpha.A0.SetInt64(tc.a0)
+		alpha.A1.SetInt64(tc.a1)
+		want, err := eisenstein.CubicResidueSymbol(&alpha, cubicCtx.Prime())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := CubicSymbolConstantTime(tc.x); int(got) != want || got != CubicSymbolFast(tc.x) {
+			t.Fatalf("symbol of %s: expected %d, got %d", tc.x.String(), want, got)
+		}
+	}
+}
+
+// cubicResidueSymbol returns the cubic residue symbol of x ≠ 0 modulo β with
+// eisenstein.CubicResidueSymbol.
+func cubicResidueSymbol(t *testing.T, x fp.Element) int {
+	var alpha eisenstein.ComplexNumber
+	x.BigInt(&alpha.A0)
+	sym, err := eisenstein.CubicResidueSymbol(&alpha, cubicCtx.Prime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sym
 }
 
 // benches
+func BenchmarkCubicSymbolBatch(b *testing.B) {
+	const nbSamples = 1 << 10
+	xs := make(fp.Vector, nbSamples)
+	for i := range xs {
+		xs[i].SetRandom()
+	}
+	dst := make([]uint8, nbSamples)
+
+	b.Run(fmt.Sprintf("%d elements-CubicSymbolFast", nbSamples), func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			for i := range xs {
+				dst[i] = CubicSymbolFast(xs[i])
+			}
+		}
+	})
+	b.Run(fmt.Sprintf("%d elements-CubicSymbolBatch", nbSamples), func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			CubicSymbolBatch(dst, xs)
+		}
+	})
+}
+
 func BenchmarkIsInSubGroupBatchNaiveShort(b *testing.B) {
 	const nbSamples = 100
 
@@ -217,7 +967,7 @@ func BenchmarkIsInSubGroupBatchShort(b *testing.B) {
 	result := BatchScalarMultiplicationG1(&g1GenAff, sampleScalars[:])
 	b.ResetTimer()
 	for j := 0; j < b.N; j++ {
-		IsInSubGroupBatch(result[:], rounds)
+		IsInSubGroupBatch(result[:], batchOptions)
 	}
 }
 
@@ -242,13 +992,37 @@ func BenchmarkComparison(b *testing.B) {
 		b.Run(fmt.Sprintf("%d points", using), func(b *testing.B) {
 			b.ResetTimer()
 			for j := 0; j < b.N; j++ {
-				IsInSubGroupBatch(result[:using], rounds)
+				IsInSubGroupBatch(result[:using], batchOptions)
 			}
 		})
 
 	}
 }
 
+// BenchmarkTatePairingsLegendre compares the Tate pairings test of order 2 of
+// checkPoint, with the binary GCD of fp.Element.Legendre, to the same test with
+// the Euler's criterion of LegendreConstantTime.
+func BenchmarkTatePairingsLegendre(b *testing.B) {
+	var a fp.Element
+	a.MustSetRandom()
+	p := MapToCurve1(&a)
+
+	b.Run("binary GCD", func(b *testing.B) {
+		for j := 0; j < b.N; j++ {
+			isFirstTateOne(p)
+		}
+	})
+	b.Run("Euler's criterion", func(b *testing.B) {
+		var tate1, tate2, one fp.Element
+		one.SetOne()
+		for j := 0; j < b.N; j++ {
+			tate1.Add(&p.X, &one)
+			tate2.Add(&p.X, &thirdRootOneG1)
+			_ = LegendreConstantTime(tate1) == 1 && LegendreConstantTime(tate2) == 1
+		}
+	})
+}
+
 // utils
 func fuzzCofactorOfG1(f fp.Element) G1Jac {
 	var res, jac G1Jac
@@ -261,3 +1035,12 @@ func fuzzCofactorOfG1(f fp.Element) G1Jac {
 	res.AddAssign(&jac)
 	return res
 }
+
+// fuzzTateOneNotInG1 returns a point of order e', which passes the Tate
+// pairings test but is not in G1.
+func fuzzTateOneNotInG1(f fp.Element) G1Jac {
+	// [6r]p kills the 2- and 3-torsion components of p.
+	res := fuzzCofactorOfG1(f)
+	res.mulWindowed(&res, big.NewInt(6))
+	return res
+}
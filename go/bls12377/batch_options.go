@@ -0,0 +1,104 @@
+package bls12377
+
+import (
+	"hash"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// The cofactor h of (E/𝔽p) is divisible by 2⁹², so a point that is not in G1
+// may have a non-trivial component of order 2. For highly 2-adic curves the
+// bound is always 2: the random scalars of a subset sum are drawn in {0,1} and
+// such a point survives one subset sum with probability at most 2⁻¹.
+//
+// Unlike the other curves, there is no Tate pairings test followed by a single
+// multi-scalar-multiplication here, as it does not help. The cofactor is
+// h = 2⁹²·3·7²·13²·499² and
+//
+//	E(𝔽p)[h] ≅ (ℤ/2⁴⁶)² × ℤ/3 × (ℤ/7)² × (ℤ/13)² × (ℤ/499)²
+//
+// so the cheap Tate pairings of order 2, as in bls12377-strong, only check
+// that a point is in 2E(𝔽p) and leave a component of order up to 2⁴⁵, which
+// any random combination misses with probability 2⁻¹. Tate pairings of order
+// 2⁴⁶ remove the 2-torsion entirely, but the primes 7, 13 and 499 still need
+// about β/2.8 combinations, and the Miller loops cost more than the subset
+// sums they save. The tests keep such a check, isInSubGroupBatchTate, which is
+// 2 to 2.5 times slower than IsInSubGroupBatch, see
+// BenchmarkIsInSubGroupBatchTate.
+//
+// In the Fiat–Shamir mode (see BatchOptions.DomainTag) the bound above holds
+// for one batch, see batch.Transcript for an adversary that can try many.
+const (
+	// DefaultSecurityLevel is the soundness in bits of the batch methods when
+	// BatchOptions.SecurityLevel is zero.
+	DefaultSecurityLevel = 64
+
+	// MaxSecurityLevel is the largest soundness in bits accepted by the batch
+	// methods.
+	MaxSecurityLevel = batch.MaxSecurityLevel
+)
+
+// ErrInvalidSecurityLevel is returned by the batch methods when
+// BatchOptions.SecurityLevel is not in [0, MaxSecurityLevel].
+var ErrInvalidSecurityLevel = batch.ErrInvalidSecurityLevel
+
+// BatchOptions configures the batch subgroup membership methods.
+// The zero value selects the default settings.
+type BatchOptions struct {
+	// SecurityLevel is the target soundness β in bits: a batch that contains
+	// a point not in G1 is accepted with probability at most 2⁻ᵝ. Zero
+	// selects DefaultSecurityLevel.
+	SecurityLevel int
+
+	// Rand is the source of the random scalars. It is only read from the
+	// calling goroutine. If nil, crypto/rand.Reader is used. A seeded
+	// math/rand/v2.ChaCha8 gives reproducible draws.
+	Rand io.Reader
+
+	// DomainTag, if not empty, selects the Fiat–Shamir mode: the random
+	// scalars are derived from a hash of DomainTag and of the serialized
+	// points instead of being read from Rand, so that the decision only
+	// depends on the input and can be replayed, e.g. by every node of a
+	// consensus protocol. DomainTag should be unique to the application.
+	DomainTag []byte
+}
+
+// rounds returns the number of random subset sums to check. For a failure
+// probability of 2⁻ᵝ we need rounds=β.
+func (opts *BatchOptions) rounds() (int, error) {
+	return opts.securityLevel()
+}
+
+// securityLevel returns the security level β of opts, or an error if it is
+// invalid.
+func (opts *BatchOptions) securityLevel() (int, error) {
+	return batch.SecurityLevel(opts.SecurityLevel, DefaultSecurityLevel)
+}
+
+// randomSources returns n sources of random bytes, one per random subset sum,
+// drawn from opts.Rand as batch.RandomSources does.
+func (opts *BatchOptions) randomSources(n int) ([]io.Reader, error) {
+	return batch.RandomSources(opts.Rand, n)
+}
+
+// fiatShamirPrefix separates the transcripts of this package from the ones of
+// the other curves.
+const fiatShamirPrefix = "batch-subgroup-membership/BLS12-377/G1"
+
+// bindPoints returns opts unchanged, except in the Fiat–Shamir mode where Rand
+// is replaced by the stream of batch.Transcript over the uncompressed
+// encodings of the points, which bind both coordinates, also for points that
+// are not on the curve.
+func (opts BatchOptions) bindPoints(points []curve.G1Affine) BatchOptions {
+	if len(opts.DomainTag) == 0 {
+		return opts
+	}
+	securityLevel, _ := opts.securityLevel()
+	opts.Rand = batch.Transcript(fiatShamirPrefix, opts.DomainTag, securityLevel, len(points), func(h hash.Hash, i int) {
+		b := points[i].RawBytes()
+		h.Write(b[:])
+	})
+	return opts
+}
@@ -0,0 +1,145 @@
+package bls12377
+
+import (
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// ErrStreamingDomainTag is returned by BatchVerifier.Verify when
+// BatchOptions.DomainTag is set: the Fiat–Shamir mode derives the random
+// scalars from all the points, which are not known while streaming.
+var ErrStreamingDomainTag = batch.ErrStreamingDomainTag
+
+// ErrVerified is returned by BatchVerifier.Add, BatchVerifier.AddMany and
+// BatchVerifier.Verify once Verify has been called.
+var ErrVerified = batch.ErrVerified
+
+// BatchVerifier checks that a stream of points P_i are in G1, as
+// IsInSubGroupBatch does for a slice, without buffering the points.
+// Each point is checked to be on the curve when it is added, and is then
+// accumulated in the running random subset sums Sj=∑[s_i]P_i, so that the
+// memory does not depend on the number of points.
+//
+// A BatchVerifier checks a single batch: Verify is terminal, see Verify.
+// A BatchVerifier is not safe for concurrent use.
+type BatchVerifier struct {
+	rounds   []batchVerifierRound
+	nbPoints int
+	rejected bool // a point is not on the curve
+	verified bool // Verify has been called
+	err      error
+}
+
+// batchVerifierWindowSize is the number of bits read at once from a source.
+const batchVerifierWindowSize = 64
+
+// batchVerifierRound accumulates a random subset sum, as subsetSumCheck does.
+type batchVerifierRound struct {
+	rng io.Reader
+	br  [batchVerifierWindowSize / 8]byte
+	sum g1JacExtended
+}
+
+// NewBatchVerifier returns an empty BatchVerifier. Errors due to opts are
+// reported by Verify.
+func NewBatchVerifier(opts BatchOptions) *BatchVerifier {
+	v := new(BatchVerifier)
+	if len(opts.DomainTag) != 0 {
+		v.err = ErrStreamingDomainTag
+		return v
+	}
+	rounds, err := opts.rounds()
+	if err != nil {
+		v.err = err
+		return v
+	}
+	sources, err := opts.randomSources(rounds)
+	if err != nil {
+		v.err = err
+		return v
+	}
+	v.rounds = make([]batchVerifierRound, rounds)
+	for i := range v.rounds {
+		v.rounds[i].rng = sources[i]
+	}
+	return v
+}
+
+// Add checks that p is on the curve and adds it to the random subset sums.
+// It returns ErrVerified if Verify has been called.
+func (v *BatchVerifier) Add(p curve.G1Affine) error {
+	if v.verified {
+		return ErrVerified
+	}
+	v.add(&p)
+	return nil
+}
+
+// AddMany adds the points one after the other, see Add.
+func (v *BatchVerifier) AddMany(points []curve.G1Affine) error {
+	if v.verified {
+		return ErrVerified
+	}
+	for i := range points {
+		v.add(&points[i])
+	}
+	return nil
+}
+
+func (v *BatchVerifier) add(p *curve.G1Affine) {
+	if v.err != nil || v.rejected {
+		return
+	}
+
+	// 1. Check p is on the curve
+	if checkPoint(p) != nil {
+		v.rejected = true
+		return
+	}
+
+	// 2. Add p to the subset sums Sj whose bit is set
+	pos := v.nbPoints % batchVerifierWindowSize
+	for i := range v.rounds {
+		round := &v.rounds[i]
+		if pos == 0 {
+			round.rng.Read(round.br[:]) // crypto/rand and ChaCha8 do not return an error, always fill br
+		}
+		if round.br[pos/8]&(1<<(pos%8)) != 0 {
+			round.sum.addMixed(p)
+		}
+	}
+	v.nbPoints++
+}
+
+// Verify reports whether all the points added are in G1, with the soundness
+// of IsInSubGroupBatch for the same options.
+//
+// Verify is terminal: the random scalars of the points added after it would
+// be drawn from the same streams, whose combinations with the points already
+// added have been checked, so Add, AddMany and Verify return ErrVerified once
+// it has been called. A new batch needs a new BatchVerifier.
+//
+// It returns an error if opts is invalid, if opts.DomainTag is set or if
+// opts.Rand fails.
+func (v *BatchVerifier) Verify() (bool, error) {
+	if v.verified {
+		return false, ErrVerified
+	}
+	v.verified = true
+	if v.err != nil {
+		return false, v.err
+	}
+	if v.rejected {
+		return false, nil
+	}
+
+	// Check Sj are on E[r]
+	for i := range v.rounds {
+		if !fromJacExtended(&v.rounds[i].sum).IsInSubGroup() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,32 @@
+package bls12377
+
+import (
+	"errors"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// Reasons for which CheckSubGroupBatch rejects a batch. They are wrapped in a
+// *BatchError and can be matched with errors.Is. The reasons that do not
+// depend on the curve are shared with the other curve packages.
+var (
+	// ErrNotOnCurve means that a point is not on the curve.
+	ErrNotOnCurve = batch.ErrNotOnCurve
+
+	// ErrRandomCombination means that a random subset sum of the points is
+	// not in G1: some point has a non-trivial component of order dividing the
+	// cofactor. FindNonMembers locates such points.
+	ErrRandomCombination = errors.New("random subset sum not in G1")
+)
+
+// BatchError is the error returned by CheckSubGroupBatch when a batch is
+// rejected. Its Index is the index of the first offending point, or -1 if it
+// is not known, as for ErrRandomCombination, and its Err is one of
+// ErrNotOnCurve or ErrRandomCombination.
+type BatchError = batch.Error
+
+// randomCombinationError returns the error of CheckSubGroupBatch for the
+// result of the random linear combinations check.
+func randomCombinationError(ok bool, err error) error {
+	return batch.CombinationError(ok, err, ErrRandomCombination)
+}
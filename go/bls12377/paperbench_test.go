@@ -28,7 +28,7 @@ func BenchmarkPaperComparison(b *testing.B) {
 		b.Run(fmt.Sprintf("%d points", using), func(b *testing.B) {
 			b.ResetTimer()
 			for j := 0; j < b.N; j++ {
-				IsInSubGroupBatch(result[:using], rounds)
+				IsInSubGroupBatch(result[:using], batchOptions)
 			}
 		})
 	}
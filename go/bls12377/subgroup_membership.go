@@ -1,7 +1,10 @@
 package bls12377
 
 import (
-	"crypto/rand"
+	"context"
+	"io"
+	"sort"
+	"sync"
 	"sync/atomic"
 
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
@@ -36,25 +39,294 @@ func IsInSubGroupBatchNaiveParallel(points []curve.G1Affine) bool {
 }
 
 // IsInSubGroupBatch checks if a batch of points P_i are in G1.
-// It generates random scalars s_i in the range [0, bound), performs
-// n=rounds multi-scalar-multiplication Sj=∑[s_i]P_i of sizes N=len(points) and
-// checks if Sj are on E[r] using Scott test [Scott21].
+// First, it checks that all points are on the curve.
+// Second, it generates random scalars s_i in {0,1}, performs n=β subset sums
+// Sj=∑[s_i]P_i of sizes N=len(points) and checks if Sj are on E[r] using Scott
+// test [Scott21], where β is the security level of opts.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
 //
 // [Scott21]: https://eprint.iacr.org/2021/1130.pdf
-func IsInSubGroupBatch(points []curve.G1Affine, rounds int) bool {
+func IsInSubGroupBatch(points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchContext(context.Background(), points, opts)
+}
+
+// IsInSubGroupBatchContext is like IsInSubGroupBatch but gives up when ctx is
+// done: the on-curve loop and the subset sums stop promptly and ctx.Err() is
+// returned.
+func IsInSubGroupBatchContext(ctx context.Context, points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	rounds, err := opts.rounds()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	for i := range points {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if checkPoint(&points[i]) != nil {
+			return false, nil
+		}
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return subsetSumCheckRounds(ctx, points, rounds, &opts)
+}
+
+func IsInSubGroupBatchParallel(points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchParallelContext(context.Background(), points, opts)
+}
+
+// IsInSubGroupBatchParallelContext is like IsInSubGroupBatchParallel but gives
+// up when ctx is done, see IsInSubGroupBatchContext.
+func IsInSubGroupBatchParallelContext(ctx context.Context, points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	rounds, err := opts.rounds()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	var nbErrors int64
+	err = parallel.ExecuteContext(ctx, len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			if checkPoint(&points[i]) != nil {
+				atomic.AddInt64(&nbErrors, 1)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+	if nbErrors > 0 {
+		return false, nil
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return subsetSumCheckRoundsParallel(ctx, points, rounds, &opts)
+}
+
+// CheckSubGroupBatch is like IsInSubGroupBatch but explains why a batch is
+// rejected. It returns nil if all the points are in G1 and otherwise a
+// *BatchError wrapping:
+//   - ErrNotOnCurve if a point is not on the curve,
+//   - ErrRandomCombination if a random subset sum is not in G1.
+//
+// Any other error comes from opts, as for IsInSubGroupBatch.
+func CheckSubGroupBatch(points []curve.G1Affine, opts BatchOptions) error {
+	rounds, err := opts.rounds()
+	if err != nil {
+		return err
+	}
+
+	// 1. Check points are on the curve
+	for i := range points {
+		if err := checkPoint(&points[i]); err != nil {
+			return &BatchError{Index: i, Err: err}
+		}
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return randomCombinationError(subsetSumCheckRounds(context.Background(), points, rounds, &opts))
+}
+
+func CheckSubGroupBatchParallel(points []curve.G1Affine, opts BatchOptions) error {
+	rounds, err := opts.rounds()
+	if err != nil {
+		return err
+	}
+
+	// 1. Check points are on the curve
+	// each worker stops at its first offending point, so the smallest one
+	// reported is the first offending point of the batch.
+	var lock sync.Mutex
+	var firstErr *BatchError
+	parallel.Execute(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if err := checkPoint(&points[i]); err != nil {
+				lock.Lock()
+				if firstErr == nil || i < firstErr.Index {
+					firstErr = &BatchError{Index: i, Err: err}
+				}
+				lock.Unlock()
+				return
+			}
+		}
+	})
+	if firstErr != nil {
+		return firstErr
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return randomCombinationError(subsetSumCheckRoundsParallel(context.Background(), points, rounds, &opts))
+}
+
+// checkPoint returns the reason why p is not on the curve, or nil.
+// The point at infinity is on the curve.
+func checkPoint(p *curve.G1Affine) error {
+	if !p.IsOnCurve() {
+		return ErrNotOnCurve
+	}
+	return nil
+}
+
+// FindNonMembers returns the indices, in increasing order, of the points P_i
+// that are not in G1.
+// First, every point that is not on the curve is reported directly.
+// Second, the remaining points are bisected using the random subset sums check
+// of IsInSubGroupBatch: a half that passes is discarded and a half that fails
+// is split again, down to single points that are checked with Scott test
+// [Scott21]. For k culprits among N points this costs O(k·log(N)) subset sums
+// checks instead of N scalar multiplications.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func FindNonMembers(points []curve.G1Affine, opts BatchOptions) ([]int, error) {
+	rounds, err := opts.rounds()
+	if err != nil {
+		return nil, err
+	}
+
+	var nonMembers []int
+
+	// 1. Points that are not on the curve are not in G1
+	candidates := points
+	var indices []int // indices[k] is the index of candidates[k] in points, nil for the identity
+	for i := range points {
+		if checkPoint(&points[i]) == nil {
+			if indices != nil {
+				candidates = append(candidates, points[i])
+				indices = append(indices, i)
+			}
+			continue
+		}
+		if indices == nil {
+			// first culprit: from now on keep track of the remaining points
+			candidates = make([]curve.G1Affine, i, len(points))
+			copy(candidates, points[:i])
+			indices = make([]int, i, len(points))
+			for k := range indices {
+				indices[k] = k
+			}
+		}
+		nonMembers = append(nonMembers, i)
+	}
+
+	// 2. Bisect the points on the curve using the random subset sums check
+	opts = opts.bindPoints(points)
+	culprits, err := bisectNonMembers(context.Background(), candidates, 0, false, rounds, &opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range culprits {
+		if indices != nil {
+			k = indices[k]
+		}
+		nonMembers = append(nonMembers, k)
+	}
+	sort.Ints(nonMembers)
+
+	return nonMembers, nil
+}
+
+// bisectNonMembers appends to culprits the offsets, shifted by offset, of the
+// points that are not in G1. If knownBad is set, points is already known to
+// fail the random subset sums check and it is not run again.
+func bisectNonMembers(ctx context.Context, points []curve.G1Affine, offset int, knownBad bool, rounds int, opts *BatchOptions, culprits []int) ([]int, error) {
+	if len(points) == 0 {
+		return culprits, nil
+	}
+	if len(points) == 1 {
+		// a single point is checked exactly with Scott test.
+		if !points[0].IsInSubGroup() {
+			culprits = append(culprits, offset)
+		}
+		return culprits, nil
+	}
+	if !knownBad {
+		ok, err := subsetSumCheckRounds(ctx, points, rounds, opts)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return culprits, nil
+		}
+	}
+
+	// if the left half passes, the culprits are in the right half.
+	mid := len(points) / 2
+	n := len(culprits)
+	culprits, err := bisectNonMembers(ctx, points[:mid], offset, false, rounds, opts, culprits)
+	if err != nil {
+		return nil, err
+	}
+	return bisectNonMembers(ctx, points[mid:], offset+mid, len(culprits) == n, rounds, opts, culprits)
+}
+
+// subsetSumCheckRounds checks that rounds random subset sums Sj=∑[s_i]P_i,
+// with s_i in {0,1} drawn from opts.Rand, are on E[r]. It returns ctx.Err()
+// if ctx is done before the check completes.
+func subsetSumCheckRounds(ctx context.Context, points []curve.G1Affine, rounds int, opts *BatchOptions) (bool, error) {
+	sources, err := opts.randomSources(rounds)
+	if err != nil {
+		return false, err
+	}
+	ok := subsetSumCheck(ctx, points, sources)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// subsetSumCheckRoundsParallel is like subsetSumCheckRounds but checks the
+// random subset sums in parallel.
+func subsetSumCheckRoundsParallel(ctx context.Context, points []curve.G1Affine, rounds int, opts *BatchOptions) (bool, error) {
+	// opts.Rand is only read from the calling goroutine, so the random sources
+	// of all the rounds are drawn beforehand.
+	sources, err := opts.randomSources(rounds)
+	if err != nil {
+		return false, err
+	}
+	var nbErrors int64
+	err = parallel.ExecuteContext(ctx, rounds, func(start, end int) {
+		if !subsetSumCheck(ctx, points, sources[start:end]) {
+			atomic.AddInt64(&nbErrors, 1)
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return nbErrors == 0, nil
+}
+
+// subsetSumCheck checks that the random subset sums Sj=∑[s_i]P_i, with s_i in
+// {0,1}, are on E[r]. The scalars of Sj are read from sources[j]. It returns
+// false early if ctx is done.
+func subsetSumCheck(ctx context.Context, points []curve.G1Affine, sources []io.Reader) bool {
 	const windowSize = 64
 	var br [windowSize / 8]byte
 
-	// Check Sj are on E[r]
-	for i := 0; i < windowSize; i++ {
+	for _, rng := range sources {
 		var sum g1JacExtended
 		for j := range len(points) {
 			pos := j % windowSize
 			if pos == 0 {
+				if ctx.Err() != nil {
+					return false
+				}
 				// re sample the random bytes every windowSize points
-				// as per the doc:
-				// Read fills b with cryptographically secure random bytes. It never returns an error, and always fills b entirely.
-				rand.Read(br[:])
+				// crypto/rand and ChaCha8 never return an error, and always fill b entirely.
+				rng.Read(br[:])
 			}
 			// check if the bit is set
 			if br[pos/8]&(1<<(pos%8)) != 0 {
@@ -70,43 +342,4 @@ func IsInSubGroupBatch(points []curve.G1Affine, rounds int) bool {
 	}
 
 	return true
-
-}
-
-func IsInSubGroupBatchParallel(points []curve.G1Affine, rounds int) bool {
-	var nbErrors int64
-	parallel.Execute(rounds, func(start, end int) {
-
-		const windowSize = 64
-		var br [windowSize / 8]byte
-
-		// Check Sj are on E[r]
-		for i := start; i < end; i++ {
-			var sum g1JacExtended
-			for j := range len(points) {
-				pos := j % windowSize
-				if pos == 0 {
-					// re sample the random bytes every windowSize points
-					// as per the doc:
-					// Read fills b with cryptographically secure random bytes. It never returns an error, and always fills b entirely.
-					rand.Read(br[:])
-				}
-				// check if the bit is set
-				if br[pos/8]&(1<<(pos%8)) != 0 {
-					// add the point to the sum
-					sum.addMixed(&points[j])
-				}
-			}
-
-			p := *fromJacExtended(&sum)
-			if !p.IsInSubGroup() {
-				atomic.AddInt64(&nbErrors, 1)
-				return
-			}
-		}
-
-	})
-
-	return nbErrors == 0
-
 }
@@ -0,0 +1,619 @@
+package bls12377
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/hash_to_curve"
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestIsInSubGroupBatchTate(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = 1
+	} else {
+		parameters.MinSuccessfulTests = 10
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	// number of points to test
+	const nbSamples = 100
+
+	properties.Property("[BLS12-377] isInSubGroupBatchTate test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			// mixer ensures that all the words of a frElement are set
+			var sampleScalars [nbSamples]fr.Element
+
+			for i := 1; i <= nbSamples; i++ {
+				sampleScalars[i-1].SetUint64(uint64(i)).
+					Mul(&sampleScalars[i-1], &mixer)
+			}
+
+			// random points in G1
+			_, _, g, _ := curve.Generators()
+			result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
+
+			ok, err := isInSubGroupBatchTate(result, batchOptions)
+			return err == nil && ok
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-377] isInSubGroupBatchTate test should not pass", prop.ForAll(
+		func(mixer fr.Element, a fp.Element) bool {
+			// mixer ensures that all the words of a frElement are set
+			var sampleScalars [nbSamples]fr.Element
+
+			for i := 1; i <= nbSamples; i++ {
+				sampleScalars[i-1].SetUint64(uint64(i)).
+					Mul(&sampleScalars[i-1], &mixer)
+			}
+
+			// random points in G1
+			_, _, g, _ := curve.Generators()
+			result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
+			// random point in the h-torsion
+			h := fuzzCofactorOfG1(a)
+			result[nbSamples/2].FromJacobian(&h)
+
+			ok, err := isInSubGroupBatchTate(result, batchOptions)
+			return err == nil && !ok
+		},
+		GenFr(),
+		GenFp(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestTatePairingsBasis(t *testing.T) {
+	t.Parallel()
+
+	// [2⁴⁵]B1 and [2⁴⁵]B2 are distinct points of order 2, so that B1 and B2,
+	// of order 2⁴⁶, generate E[2⁴⁶].
+	var halves [2]curve.G1Jac
+	for i, b := range []curve.G1Affine{tateB1, tateB2} {
+		if !b.IsOnCurve() {
+			t.Fatalf("B%d is not on the curve", i+1)
+		}
+		halves[i].FromAffine(&b)
+		for range tateLog2 - 1 {
+			halves[i].DoubleAssign()
+		}
+		if halves[i].Z.IsZero() {
+			t.Fatalf("[2⁴⁵]B%d is the point at infinity", i+1)
+		}
+		var double curve.G1Jac
+		double.Double(&halves[i])
+		if !double.Z.IsZero() {
+			t.Fatalf("[2⁴⁶]B%d is not the point at infinity", i+1)
+		}
+	}
+	if halves[0].Equal(&halves[1]) {
+		t.Fatal("[2⁴⁵]B1 == [2⁴⁵]B2")
+	}
+}
+
+func TestIsInSubGroupBatchTateSmallTorsion(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+	var one, omega fp.Element
+	one.SetOne()
+	omega.SetString("80949648264912719408558363140637477264845294720710499478137287262712535938301461879813459410945")
+
+	var torsion []curve.G1Affine
+	// the points of order 2, (-1,0), (-ω,0) and (-ω²,0)
+	var x fp.Element
+	x.Neg(&one)
+	for range 3 {
+		torsion = append(torsion, curve.G1Affine{X: x})
+		x.Mul(&x, &omega)
+	}
+	// the points of order 3, (0,1) and (0,-1)
+	var minusOne fp.Element
+	minusOne.Neg(&one)
+	torsion = append(torsion, curve.G1Affine{Y: one}, curve.G1Affine{Y: minusOne})
+	// B1, [2²³]B1+B2 and [2⁴⁴]B1, of order 2⁴⁶, 2⁴⁶ and 4
+	for i, k := range []int{0, 23, 44} {
+		var q curve.G1Jac
+		q.FromAffine(&tateB1)
+		for range k {
+			q.DoubleAssign()
+		}
+		if i == 1 {
+			var b2 curve.G1Jac
+			b2.FromAffine(&tateB2)
+			q.AddAssign(&b2)
+		}
+		var qAff curve.G1Affine
+		qAff.FromJacobian(&q)
+		torsion = append(torsion, qAff)
+	}
+
+	opts := BatchOptions{SecurityLevel: 128}
+	for _, q := range torsion {
+		if !q.IsOnCurve() {
+			t.Fatalf("%s is not on the curve", q.String())
+		}
+		var sum curve.G1Affine
+		sum.Add(&q, &g)
+		for _, points := range [][]curve.G1Affine{{q}, {g, q}, {sum, g}} {
+			if isTateOneAggregated(points, 128, 81, mrand.NewChaCha8([32]byte{})) {
+				t.Fatalf("%s: the Tate pairings test should not pass", q.String())
+			}
+			ok, err := isInSubGroupBatchTate(points, opts)
+			if err != nil || ok {
+				t.Fatalf("%s: isInSubGroupBatchTate should not pass: ok=%v err=%v", q.String(), ok, err)
+			}
+		}
+	}
+
+	// points of order 7, 13 and 499 pass the Tate pairings test and are
+	// rejected by the random linear combinations
+	// exponent of E(𝔽p) = r·2⁴⁶·3·7·13·499
+	m := new(big.Int).Lsh(big.NewInt(3*7*13*499), tateLog2)
+	m.Mul(m, fr.Modulus())
+	for _, l := range []int64{7, 13, 499} {
+		q := smallOrderPoint(m, l)
+		var sum curve.G1Affine
+		sum.Add(&q, &g)
+		for _, points := range [][]curve.G1Affine{{q}, {g, q}, {sum, g}} {
+			if !isTateOneAggregated(points, 128, 81, mrand.NewChaCha8([32]byte{})) {
+				t.Fatalf("order %d: the Tate pairings test should pass", l)
+			}
+			ok, err := isInSubGroupBatchTate(points, opts)
+			if err != nil || ok {
+				t.Fatalf("order %d: isInSubGroupBatchTate should not pass: ok=%v err=%v", l, ok, err)
+			}
+		}
+	}
+}
+
+func TestIsInSubGroupBatchTateOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		securityLevel, rounds2, rounds3, msmRounds int
+	}{
+		{0, 64, 41, 23},
+		{1, 1, 1, 1},
+		{64, 64, 41, 23},
+		{128, 128, 81, 46},
+	} {
+		opts := BatchOptions{SecurityLevel: tc.securityLevel}
+		rounds2, rounds3, err := opts.tateRounds()
+		if err != nil {
+			t.Fatalf("security level %d: unexpected error: %v", tc.securityLevel, err)
+		}
+		msmRounds, err := opts.msmRounds()
+		if err != nil {
+			t.Fatalf("security level %d: unexpected error: %v", tc.securityLevel, err)
+		}
+		if rounds2 != tc.rounds2 || rounds3 != tc.rounds3 || msmRounds != tc.msmRounds {
+			t.Fatalf("security level %d: expected %d, %d and %d rounds, got %d, %d and %d", tc.securityLevel,
+				tc.rounds2, tc.rounds3, tc.msmRounds, rounds2, rounds3, msmRounds)
+		}
+	}
+
+	_, _, g, _ := curve.Generators()
+	points := []curve.G1Affine{g}
+	for _, securityLevel := range []int{-1, MaxSecurityLevel + 1} {
+		opts := BatchOptions{SecurityLevel: securityLevel}
+		if _, err := isInSubGroupBatchTate(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+	}
+	if _, err := isInSubGroupBatchTate(points, BatchOptions{Rand: failingReader{}}); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+
+	// the point at infinity is in G1
+	opts := BatchOptions{DomainTag: []byte("test")}
+	if ok, err := isInSubGroupBatchTate(append(points, curve.G1Affine{}), opts); err != nil || !ok {
+		t.Fatalf("expected ok, got ok=%v err=%v", ok, err)
+	}
+}
+
+// benches
+func BenchmarkIsInSubGroupBatchTate(b *testing.B) {
+	const (
+		pow       = 14
+		nbSamples = 1 << pow
+	)
+	var sampleScalars [nbSamples]fr.Element
+	fillBenchScalars(sampleScalars[:])
+
+	_, _, g, _ := curve.Generators()
+	result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
+
+	for i := 8; i <= pow; i += 2 {
+		using := 1 << i
+		b.Run(fmt.Sprintf("%d points-subset sums", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatch(result[:using], batchOptions)
+			}
+		})
+		b.Run(fmt.Sprintf("%d points-tate", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				isInSubGroupBatchTate(result[:using], batchOptions)
+			}
+		})
+	}
+}
+
+// utils
+
+// isInSubGroupBatchTate checks if a batch of points P_i are in G1 as the strong
+// curves do: a Tate pairings test of the small torsion followed by random
+// linear combinations with multi-bit scalars. It is kept in the tests only, to
+// measure it against IsInSubGroupBatch: it is 2 to 2.5 times slower, see
+// BenchmarkIsInSubGroupBatchTate and batch_options.go.
+//
+// The cofactor is h = 2⁹²·3·7²·13²·499² and
+//
+//	E(𝔽p)[h] ≅ (ℤ/2⁴⁶)² × ℤ/3 × (ℤ/7)² × (ℤ/13)² × (ℤ/499)²
+//
+// First, it checks that all points are on the curve and that their components
+// of order 2⁴⁶ and 3 are trivial. 2⁴⁶ and 3 divide p-1, so the component of
+// order 2⁴⁶ of Q is trivial iff the reduced Tate pairings of order 2⁴⁶ of a
+// basis (B1, B2) of E[2⁴⁶] with Q are 1, and its component of order 3 iff the
+// Tate pairing of order 3 of P3 = (0,1) with Q, i.e. the cubic character
+// χ₃(y-1), is 1. As in IsInSubGroupBatchAggregated of the strong curves, each
+// round checks that
+//
+//	(∏f_{2⁴⁶,B1}(Q_i)^{a_i}·f_{2⁴⁶,B2}(Q_i)^{b_i})^((p-1)/2⁴⁶) == 1 and χ₃(∏(y_i-1)^{c_i}) == 1
+//
+// for random a_i, b_i ∈ {0,1} and c_i ∈ {0,1,2}, which misses a non-trivial
+// component with probability at most 1/2, resp. 1/3, so β rounds of order 2⁴⁶
+// and ⌈β/log₂(3)⌉ rounds of order 3 are run. The two Miller functions, of 46
+// steps each (45 tangents and the vertical line of the last step), are
+// evaluated once per point, and alone cost more than the β subset sums of
+// IsInSubGroupBatch. A zero line, i.e. a point Q of order
+// dividing 2⁴⁶ or P3, is rejected directly.
+//
+// Second, it generates random scalars s_i in [0, 2⁸), and checks that the
+// linear combinations Sj=∑[s_i]P_i are on E[r] using Scott test [Scott21].
+// What is left of a point not in G1 is a component of order 7, 13 or 499,
+// which a combination misses with probability at most ⌈2⁸/7⌉/2⁸ = 37/256, so
+// ⌈β/log₂(256/37)⌉ combinations are needed. Unlike on the strong curves, a
+// single combination can't reach 2⁻ᵝ since all the primes of h are smaller
+// than 2⁹.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func isInSubGroupBatchTate(points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	rounds2, rounds3, err := opts.tateRounds()
+	if err != nil {
+		return false, err
+	}
+	rounds, err := opts.msmRounds()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	for i := range points {
+		if checkPoint(&points[i]) != nil {
+			return false, nil
+		}
+	}
+
+	// 2. Check points are on E[r*7²*13²*499²] with aggregated Tate pairings
+	opts = opts.bindPoints(points)
+	sources, err := opts.randomSources(1)
+	if err != nil {
+		return false, err
+	}
+	if !isTateOneAggregated(points, rounds2, rounds3, sources[0]) {
+		return false, nil
+	}
+
+	// 3. Check Sj are on E[r]
+	sources, err = opts.randomSources(rounds)
+	if err != nil {
+		return false, err
+	}
+	for _, rng := range sources {
+		if !msmCheck(points, rng) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// tateRounds returns the number of rounds of the aggregated Tate pairings test
+// of order 2⁴⁶ and 3, see isInSubGroupBatchTate.
+func (opts *BatchOptions) tateRounds() (rounds2, rounds3 int, err error) {
+	securityLevel, err := opts.securityLevel()
+	if err != nil {
+		return 0, 0, err
+	}
+	rounds2, rounds3 = batch.TateRounds(securityLevel)
+	return rounds2, rounds3, nil
+}
+
+// msmRounds returns the number of random linear combinations of
+// isInSubGroupBatchTate. For a failure probability of 2⁻ᵝ we need
+// rounds=⌈β/log₂(256/37)⌉, e.g. β=64 gives 23 rounds.
+func (opts *BatchOptions) msmRounds() (int, error) {
+	securityLevel, err := opts.securityLevel()
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Ceil(float64(securityLevel) / math.Log2(256.0/37))), nil
+}
+
+// tateLog2 is the 2-adic valuation of p-1, and the exponent of E(𝔽p)[2^∞] is
+// 2^tateLog2.
+const tateLog2 = 46
+
+// millerStep is a doubling step T → 2T of a Miller loop: the tangent line at
+// T, y + ax + b, and the vertical line at 2T, x + v.
+type millerStep struct {
+	a, b, v fp.Element
+}
+
+var (
+	// tateB1 and tateB2 are a basis of E[2⁴⁶]:
+	// tateB1 = [(r·h)/2⁹²](8, √(8³+1)) and tateB2 = [(r·h)/2⁹²](14, √(14³+1)).
+	tateB1, tateB2 curve.G1Affine
+
+	// millerB1 and millerB2 are the doubling steps of the Miller functions
+	// f_{2⁴⁶,B1} and f_{2⁴⁶,B2}, up to [2⁴⁵]B, of order 2, whose tangent is
+	// the vertical line of the last step.
+	millerB1, millerB2 [tateLog2 - 1]millerStep
+
+	tateExp2 big.Int // (p-1)/2⁴⁶
+	tateExp3 big.Int // (p-1)/3
+)
+
+func init() {
+	// [(r·h)/2⁹²]P is the component of order dividing 2⁴⁶ of P
+	e := new(big.Int).Mul(fr.Modulus(), big.NewInt(3*7*7*13*13*499*499))
+	tateB1 = tatePoint(8, e)
+	tateB2 = tatePoint(14, e)
+	millerSteps(&millerB1, tateB1)
+	millerSteps(&millerB2, tateB2)
+
+	tateExp2.Sub(fp.Modulus(), big.NewInt(1)).Rsh(&tateExp2, tateLog2)
+	tateExp3.Sub(fp.Modulus(), big.NewInt(1)).Div(&tateExp3, big.NewInt(3))
+}
+
+// tatePoint returns [e](x, √(x³+1)).
+func tatePoint(x uint64, e *big.Int) curve.G1Affine {
+	var p curve.G1Affine
+	var y2, one fp.Element
+	one.SetOne()
+	p.X.SetUint64(x)
+	y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &one)
+	if p.Y.Sqrt(&y2) == nil {
+		panic("bls12377: x³+1 is not a square")
+	}
+	return mulBig(&p, e)
+}
+
+// millerSteps sets steps to the doubling steps of the Miller loop of order 2⁴⁶
+// of t, a point of order 2⁴⁶.
+//
+// The tangent line at T=(x1,y1), with λ = 3x1²/(2y1), has:
+//
+//	a = -λ
+//	b = λx1 - y1
+//
+// and 2T = (x3, y3) = (λ² - 2x1, λ(x1 - x3) - y1).
+func millerSteps(steps *[tateLog2 - 1]millerStep, t curve.G1Affine) {
+	for k := range steps {
+		var lambda, denom, x3, y3 fp.Element
+		lambda.Square(&t.X)
+		denom.Double(&lambda)
+		lambda.Add(&lambda, &denom)
+		denom.Double(&t.Y)
+		lambda.Div(&lambda, &denom)
+
+		steps[k].a.Neg(&lambda)
+		steps[k].b.Mul(&lambda, &t.X).Sub(&steps[k].b, &t.Y)
+
+		x3.Square(&lambda).Sub(&x3, &t.X).Sub(&x3, &t.X)
+		y3.Sub(&t.X, &x3).Mul(&y3, &lambda).Sub(&y3, &t.Y)
+		t.X, t.Y = x3, y3
+		steps[k].v.Neg(&x3)
+	}
+	if !t.Y.IsZero() {
+		panic("bls12377: basis point not of order 2⁴⁶")
+	}
+}
+
+// millerLoop returns the numerator and the denominator of f_{2⁴⁶,B}(q), where
+// steps are the doubling steps of B, or ok=false if a line vanishes at q,
+// which is then a point of order dividing 2⁴⁶.
+//
+// f_{2^(k+1),B} = f_{2^k,B}²·l_k/v_{k+1} where l_k is the tangent at [2^k]B and
+// v_{k+1} the vertical line at [2^(k+1)]B. The tangent at [2⁴⁵]B is x + v of
+// the last step, and [2⁴⁶]B = O has no vertical line.
+func millerLoop(steps *[tateLog2 - 1]millerStep, q *curve.G1Affine) (num, den fp.Element, ok bool) {
+	var l, v fp.Element
+	num.SetOne()
+	den.SetOne()
+	for k := range steps {
+		l.Mul(&steps[k].a, &q.X).Add(&l, &q.Y).Add(&l, &steps[k].b)
+		v.Add(&q.X, &steps[k].v)
+		if l.IsZero() || v.IsZero() {
+			return num, den, false
+		}
+		num.Square(&num).Mul(&num, &l)
+		den.Square(&den).Mul(&den, &v)
+	}
+	// v is the vertical line at [2⁴⁵]B
+	num.Square(&num).Mul(&num, &v)
+	den.Square(&den)
+	return num, den, true
+}
+
+// isTateOneAggregated checks that the components of order 2⁴⁶ and 3 of all
+// the points are trivial, except with probability 2⁻ᵝ, using rounds2 and
+// rounds3 random products drawn from rng. The points must be on the curve.
+func isTateOneAggregated(points []curve.G1Affine, rounds2, rounds3 int, rng io.Reader) bool {
+	// f_{2⁴⁶,B1}(Q_i) and f_{2⁴⁶,B2}(Q_i), as fractions
+	nums := make([]fp.Element, 0, 2*len(points))
+	dens := make([]fp.Element, 0, 2*len(points))
+	for i := range points {
+		// the point at infinity is in G1.
+		if points[i].IsInfinity() {
+			continue
+		}
+		num1, den1, ok1 := millerLoop(&millerB1, &points[i])
+		num2, den2, ok2 := millerLoop(&millerB2, &points[i])
+		if !ok1 || !ok2 {
+			return false
+		}
+		nums = append(nums, num1, num2)
+		dens = append(dens, den1, den2)
+	}
+	dens = fp.BatchInvert(dens)
+	for i := range nums {
+		nums[i].Mul(&nums[i], &dens[i])
+	}
+
+	// running products, one per round
+	acc2 := make([]fp.Element, rounds2)
+	acc3 := make([]fp.Element, rounds3)
+	for i := range acc2 {
+		acc2[i].SetOne()
+	}
+	for i := range acc3 {
+		acc3[i].SetOne()
+	}
+
+	exps := batch.NewExponentReader(rng)
+	var one, tate3, tate3Sq fp.Element
+	one.SetOne()
+	k := 0
+	for i := range points {
+		if points[i].IsInfinity() {
+			continue
+		}
+		for j := range acc2 {
+			e := exps.Bits2()
+			if e&1 != 0 {
+				acc2[j].Mul(&acc2[j], &nums[k])
+			}
+			if e&2 != 0 {
+				acc2[j].Mul(&acc2[j], &nums[k+1])
+			}
+		}
+		k += 2
+
+		// y-1 only vanishes at (0,1), of order 3.
+		tate3.Sub(&points[i].Y, &one)
+		if tate3.IsZero() {
+			return false
+		}
+		tate3Sq.Square(&tate3)
+		for j := range acc3 {
+			switch exps.Trit() {
+			case 1:
+				acc3[j].Mul(&acc3[j], &tate3)
+			case 2:
+				acc3[j].Mul(&acc3[j], &tate3Sq)
+			}
+		}
+	}
+
+	for j := range acc2 {
+		if !acc2[j].Exp(acc2[j], &tateExp2).IsOne() {
+			return false
+		}
+	}
+	for j := range acc3 {
+		if !acc3[j].Exp(acc3[j], &tateExp3).IsOne() {
+			return false
+		}
+	}
+	return true
+}
+
+// msmCheck checks that the random linear combination S=∑[s_i]P_i, with s_i in
+// [0, 2⁸) read from rng, is on E[r]. S is computed with the bucket method:
+// P_i is added to the bucket s_i and S=∑[k]bucket_k.
+func msmCheck(points []curve.G1Affine, rng io.Reader) bool {
+	const windowSize = 64
+	var br [windowSize]byte
+
+	var buckets [255]g1JacExtended
+	for k := range buckets {
+		buckets[k].SetInfinity()
+	}
+	for j := range points {
+		pos := j % windowSize
+		if pos == 0 {
+			// re sample the random bytes every windowSize points
+			// crypto/rand and ChaCha8 never return an error, and always fill br entirely.
+			rng.Read(br[:])
+		}
+		if br[pos] != 0 {
+			buckets[br[pos]-1].addMixed(&points[j])
+		}
+	}
+
+	// running = ∑_{l≥k} bucket_l, sum = ∑_k running_k
+	var running, sum curve.G1Jac
+	running.X.SetOne()
+	running.Y.SetOne()
+	sum.Set(&running)
+	for k := len(buckets) - 1; k >= 0; k-- {
+		running.AddAssign(fromJacExtended(&buckets[k]))
+		sum.AddAssign(&running)
+	}
+	return sum.IsInSubGroup()
+}
+
+// smallOrderPoint returns a point of order l, where l is a prime dividing the
+// cofactor and m is the exponent of E(𝔽p).
+func smallOrderPoint(m *big.Int, l int64) curve.G1Affine {
+	e := new(big.Int).Div(m, big.NewInt(l))
+	var f fp.Element
+	for i := uint64(1); ; i++ {
+		f.SetUint64(i)
+		aff := curve.MapToCurve1(&f)
+		hash_to_curve.G1Isogeny(&aff.X, &aff.Y)
+		if q := mulBig(&aff, e); !q.IsInfinity() {
+			return q
+		}
+	}
+}
+
+// mulBig returns [e]P, with a double-and-add as ScalarMultiplication reduces
+// the scalar modulo r.
+func mulBig(p *curve.G1Affine, e *big.Int) curve.G1Affine {
+	var res, base curve.G1Jac
+	res.X.SetOne()
+	res.Y.SetOne()
+	base.FromAffine(p)
+	for j := e.BitLen() - 1; j >= 0; j-- {
+		res.DoubleAssign()
+		if e.Bit(j) == 1 {
+			res.AddAssign(&base)
+		}
+	}
+	var q curve.G1Affine
+	q.FromJacobian(&res)
+	return q
+}
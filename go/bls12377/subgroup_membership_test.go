@@ -1,8 +1,13 @@
 package bls12377
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
 	"testing"
+	"time"
 
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
@@ -14,9 +19,10 @@ import (
 )
 
 // For highly 2-adic curves the bound is always 2.
-// For a failure probability of 2⁻ᵝ we need to set rounds=β.
+// For a failure probability of 2⁻ᵝ we need rounds=β, which is what
+// BatchOptions{SecurityLevel: β} selects.
 // For example β=64 gives rounds=64 and β=128 gives rounds=128.
-var rounds = 64
+var batchOptions = BatchOptions{SecurityLevel: DefaultSecurityLevel}
 
 func TestIsInSubGroupBatch(t *testing.T) {
 	t.Parallel()
@@ -90,7 +96,8 @@ func TestIsInSubGroupBatch(t *testing.T) {
 			_, _, g, _ := curve.Generators()
 			result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
 
-			return IsInSubGroupBatch(result, rounds)
+			ok, err := IsInSubGroupBatch(result, batchOptions)
+			return err == nil && ok
 		},
 		GenFr(),
 	))
@@ -114,15 +121,503 @@ func TestIsInSubGroupBatch(t *testing.T) {
 			h = fuzzCofactorOfG1(a)
 			result[nbSamples-1].FromJacobian(&h)
 
-			return !IsInSubGroupBatch(result, rounds)
+			ok, err := IsInSubGroupBatch(result, batchOptions)
+			return err == nil && !ok
 		},
 		GenFr(),
 		GenFp(),
 	))
 
+	properties.Property("[BLS12-377] FindNonMembers should return the indices of the points not in G1", prop.ForAll(
+		func(mixer fr.Element, a fp.Element) bool {
+			// mixer ensures that all the words of a frElement are set
+			var sampleScalars [nbSamples]fr.Element
+
+			for i := 1; i <= nbSamples; i++ {
+				sampleScalars[i-1].SetUint64(uint64(i)).
+					Mul(&sampleScalars[i-1], &mixer)
+			}
+
+			// random points in G1
+			_, _, g, _ := curve.Generators()
+			result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
+
+			// random points in the h-torsion
+			h := fuzzCofactorOfG1(a)
+			result[0].FromJacobian(&h)
+			h = fuzzCofactorOfG1(a)
+			result[nbSamples/2].FromJacobian(&h)
+			h = fuzzCofactorOfG1(a)
+			result[nbSamples-1].FromJacobian(&h)
+
+			var expected []int
+			for i := range result {
+				if !result[i].IsInSubGroup() {
+					expected = append(expected, i)
+				}
+			}
+
+			nonMembers, err := FindNonMembers(result, batchOptions)
+			if err != nil {
+				return false
+			}
+			if len(nonMembers) != len(expected) {
+				return false
+			}
+			for i := range expected {
+				if nonMembers[i] != expected[i] {
+					return false
+				}
+			}
+			return true
+		},
+		GenFr(),
+		GenFp(),
+	))
+
+	properties.Property("[BLS12-377] FindNonMembers should return no index for points in G1", prop.ForAll(
+		func(mixer fr.Element) bool {
+			// mixer ensures that all the words of a frElement are set
+			var sampleScalars [nbSamples]fr.Element
+
+			for i := 1; i <= nbSamples; i++ {
+				sampleScalars[i-1].SetUint64(uint64(i)).
+					Mul(&sampleScalars[i-1], &mixer)
+			}
+
+			// random points in G1
+			_, _, g, _ := curve.Generators()
+			result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
+
+			nonMembers, err := FindNonMembers(result, batchOptions)
+			return err == nil && len(nonMembers) == 0
+		},
+		GenFr(),
+	))
+
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestBatchOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		securityLevel int
+		rounds        int
+	}{
+		{0, 64},
+		{1, 1},
+		{64, 64},
+		{128, 128},
+	} {
+		opts := BatchOptions{SecurityLevel: tc.securityLevel}
+		rounds, err := opts.rounds()
+		if err != nil {
+			t.Fatalf("security level %d: unexpected error: %v", tc.securityLevel, err)
+		}
+		if rounds != tc.rounds {
+			t.Fatalf("security level %d: expected %d rounds, got %d", tc.securityLevel, tc.rounds, rounds)
+		}
+	}
+
+	_, _, g, _ := curve.Generators()
+	points := []curve.G1Affine{g}
+	for _, securityLevel := range []int{-1, MaxSecurityLevel + 1} {
+		opts := BatchOptions{SecurityLevel: securityLevel}
+		if _, err := IsInSubGroupBatch(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+		if _, err := IsInSubGroupBatchParallel(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+		if _, err := FindNonMembers(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+	}
+}
+
+// failingReader is an io.Reader that always fails.
+type failingReader struct{}
+
+var errFailingReader = errors.New("failing reader")
+
+func (failingReader) Read([]byte) (int, error) { return 0, errFailingReader }
+
+func TestBatchOptionsRand(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+	var f fp.Element
+	f.SetRandom()
+	points := make([]curve.G1Affine, 16)
+	for i := range points {
+		points[i] = g
+	}
+	q := fuzzCofactorOfG1(f)
+	points[5].FromJacobian(&q)
+
+	// the same seed draws the same random subset sums, whatever the
+	// scheduling of the rounds
+	for seed := range byte(16) {
+		opts := BatchOptions{SecurityLevel: 4, Rand: mrand.NewChaCha8([32]byte{seed})}
+		ok1, err := IsInSubGroupBatch(points, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.Rand = mrand.NewChaCha8([32]byte{seed})
+		ok2, err := IsInSubGroupBatchParallel(points, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok1 != ok2 {
+			t.Fatalf("seed %d: serial and parallel checks disagree", seed)
+		}
+	}
+
+	// a failing source of randomness is reported
+	opts := BatchOptions{Rand: failingReader{}}
+	if _, err := IsInSubGroupBatch(points, opts); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+	if _, err := IsInSubGroupBatchParallel(points, opts); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+	if _, err := FindNonMembers(points, opts); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+}
+
+func TestBatchOptionsFiatShamir(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+	points := make([]curve.G1Affine, 16)
+	for i := range points {
+		points[i].ScalarMultiplication(&g, big.NewInt(int64(i+1)))
+	}
+
+	// the transcript seed only depends on the domain tag, the security level
+	// and the points
+	seed := func(opts BatchOptions, points []curve.G1Affine) [32]byte {
+		var res [32]byte
+		if _, err := opts.bindPoints(points).Rand.Read(res[:]); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	opts := BatchOptions{DomainTag: []byte("test")}
+	s := seed(opts, points)
+	if seed(opts, points) != s {
+		t.Fatal("same transcript should give the same seed")
+	}
+	if seed(BatchOptions{DomainTag: []byte("tset")}, points) == s {
+		t.Fatal("different domain tags should give different seeds")
+	}
+	if seed(BatchOptions{DomainTag: []byte("test"), SecurityLevel: 1}, points) == s {
+		t.Fatal("different security levels should give different seeds")
+	}
+	if seed(opts, points[1:]) == s {
+		t.Fatal("different points should give different seeds")
+	}
+	if opts := (BatchOptions{}).bindPoints(points); opts.Rand != nil {
+		t.Fatal("bindPoints should not change opts without a domain tag")
+	}
+
+	// the decision is the same on every run
+	if ok, err := IsInSubGroupBatch(points, opts); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	if ok, err := IsInSubGroupBatchParallel(points, opts); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	var f fp.Element
+	f.SetRandom()
+	q := fuzzCofactorOfG1(f)
+	points[5].FromJacobian(&q)
+	for range 2 {
+		if ok, err := IsInSubGroupBatch(points, opts); err != nil || ok {
+			t.Fatalf("expected points not in G1, got %v, %v", ok, err)
+		}
+		if ok, err := IsInSubGroupBatchParallel(points, opts); err != nil || ok {
+			t.Fatalf("expected points not in G1, got %v, %v", ok, err)
+		}
+		nonMembers, err := FindNonMembers(points, opts)
+		if err != nil || fmt.Sprint(nonMembers) != "[5]" {
+			t.Fatalf("expected [5], got %v, %v", nonMembers, err)
+		}
+	}
+}
+
+func TestCheckSubGroupBatch(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+
+	// points rejected for a known reason
+	offCurve := g
+	offCurve.Y.Double(&offCurve.Y)
+
+	var f fp.Element
+	f.SetRandom()
+	q := fuzzCofactorOfG1(f)
+	var notInG1 curve.G1Affine
+	notInG1.FromJacobian(&q)
+
+	cases := []struct {
+		name  string
+		point curve.G1Affine
+		err   error
+		index int
+	}{
+		{"not on the curve", offCurve, ErrNotOnCurve, 3},
+		{"random subset sum", notInG1, ErrRandomCombination, -1},
+	}
+
+	points := make([]curve.G1Affine, 16)
+	for i := range points {
+		points[i] = g
+	}
+	checks := []func([]curve.G1Affine, BatchOptions) error{CheckSubGroupBatch, CheckSubGroupBatchParallel}
+	for _, check := range checks {
+		if err := check(points, batchOptions); err != nil {
+			t.Fatalf("expected points in G1, got %v", err)
+		}
+		if err := check(points, BatchOptions{SecurityLevel: -1}); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("expected ErrInvalidSecurityLevel, got %v", err)
+		}
+	}
+
+	for _, tc := range cases {
+		// the first offending point is reported
+		bad := make([]curve.G1Affine, len(points))
+		copy(bad, points)
+		bad[3], bad[11] = tc.point, tc.point
+		for _, check := range checks {
+			err := check(bad, batchOptions)
+			var batchErr *BatchError
+			if !errors.Is(err, tc.err) || !errors.As(err, &batchErr) || batchErr.Index != tc.index {
+				t.Fatalf("%s: expected %v at index %d, got %v", tc.name, tc.err, tc.index, err)
+			}
+		}
+	}
+}
+
+func TestIsInSubGroupBatchInvalidPoints(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+	points := make([]curve.G1Affine, 16)
+	for i := range points {
+		points[i].ScalarMultiplication(&g, big.NewInt(int64(i+1)))
+	}
+
+	// b = y²-x³ for the generator
+	var b, x3 fp.Element
+	b.Square(&g.Y)
+	x3.Square(&g.X).Mul(&x3, &g.X)
+	b.Sub(&b, &x3)
+
+	// a point on the quadratic twist d·y² = x³+b, with d a non-square
+	var d fp.Element
+	for d.SetRandom(); d.Legendre() != -1; d.SetRandom() {
+	}
+	var twist curve.G1Affine
+	for {
+		var rhs fp.Element
+		twist.X.SetRandom()
+		rhs.Square(&twist.X).Mul(&rhs, &twist.X).Add(&rhs, &b).Div(&rhs, &d)
+		if twist.Y.Sqrt(&rhs) != nil {
+			break
+		}
+	}
+
+	// a point neither on the curve nor on its twist
+	offCurve := g
+	offCurve.Y.Double(&offCurve.Y)
+
+	for _, tc := range []struct {
+		name  string
+		point curve.G1Affine
+	}{
+		{"twist", twist},
+		{"not on the curve", offCurve},
+	} {
+		bad := make([]curve.G1Affine, len(points))
+		copy(bad, points)
+		bad[6] = tc.point
+
+		if ok, err := IsInSubGroupBatch(bad, batchOptions); err != nil || ok {
+			t.Fatalf("%s: expected points not in G1, got %v, %v", tc.name, ok, err)
+		}
+		if ok, err := IsInSubGroupBatchParallel(bad, batchOptions); err != nil || ok {
+			t.Fatalf("%s: expected points not in G1, got %v, %v", tc.name, ok, err)
+		}
+		if nonMembers, err := FindNonMembers(bad, batchOptions); err != nil || fmt.Sprint(nonMembers) != "[6]" {
+			t.Fatalf("%s: expected [6], got %v, %v", tc.name, nonMembers, err)
+		}
+		if err := CheckSubGroupBatch(bad, batchOptions); !errors.Is(err, ErrNotOnCurve) {
+			t.Fatalf("%s: expected ErrNotOnCurve, got %v", tc.name, err)
+		}
+	}
+
+	// the point at infinity is in G1
+	var infinity curve.G1Affine
+	withInfinity := make([]curve.G1Affine, len(points))
+	copy(withInfinity, points)
+	withInfinity[0], withInfinity[9] = infinity, infinity
+	for _, batch := range [][]curve.G1Affine{withInfinity, {infinity}, {infinity, infinity}} {
+		if ok, err := IsInSubGroupBatch(batch, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G1, got %v, %v", ok, err)
+		}
+		if ok, err := IsInSubGroupBatchParallel(batch, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G1, got %v, %v", ok, err)
+		}
+		if nonMembers, err := FindNonMembers(batch, batchOptions); err != nil || len(nonMembers) != 0 {
+			t.Fatalf("expected no index, got %v, %v", nonMembers, err)
+		}
+		if err := CheckSubGroupBatch(batch, batchOptions); err != nil {
+			t.Fatalf("expected points in G1, got %v", err)
+		}
+	}
+}
+
+func TestIsInSubGroupBatchContext(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+	points := make([]curve.G1Affine, 1<<12)
+	for i := range points {
+		points[i] = g
+	}
+	checks := []func(context.Context, []curve.G1Affine, BatchOptions) (bool, error){
+		IsInSubGroupBatchContext,
+		IsInSubGroupBatchParallelContext,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, check := range checks {
+		if ok, err := check(ctx, points, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G1, got %v, %v", ok, err)
+		}
+	}
+
+	// a cancelled context is reported
+	cancel()
+	for _, check := range checks {
+		if _, err := check(ctx, points, batchOptions); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+
+	// the subset sums stop too
+	if _, err := subsetSumCheckRounds(ctx, points, 1, &batchOptions); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// a deadline in the middle of the checks is honoured
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := IsInSubGroupBatchContext(ctx, points, batchOptions); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestBatchVerifier(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+	points := make([]curve.G1Affine, 200)
+	for i := range points {
+		points[i].ScalarMultiplication(&g, big.NewInt(int64(i+1)))
+	}
+	var f fp.Element
+	f.SetRandom()
+	q := fuzzCofactorOfG1(f)
+	var notInG1 curve.G1Affine
+	notInG1.FromJacobian(&q)
+
+	// points streamed one by one and in slices, across several windows
+	stream := func(opts BatchOptions, points []curve.G1Affine) (bool, error) {
+		v := NewBatchVerifier(opts)
+		v.Add(points[0])
+		v.AddMany(points[1:100])
+		for i := 100; i < len(points); i++ {
+			v.Add(points[i])
+		}
+		return v.Verify()
+	}
+	if ok, err := stream(batchOptions, points); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	for _, i := range []int{0, 64, 150, len(points) - 1} {
+		bad := make([]curve.G1Affine, len(points))
+		copy(bad, points)
+		bad[i] = notInG1
+		if ok, err := stream(batchOptions, bad); err != nil || ok {
+			t.Fatalf("point %d: expected points not in G1, got %v, %v", i, ok, err)
+		}
+	}
+
+	// points that are not on the curve are rejected when added
+	offCurve := g
+	offCurve.Y.Double(&offCurve.Y)
+	v := NewBatchVerifier(batchOptions)
+	v.AddMany(points[:10])
+	v.Add(offCurve)
+	if !v.rejected {
+		t.Fatal("expected the point to be rejected when added")
+	}
+	v.AddMany(points[10:])
+	if ok, err := v.Verify(); err != nil || ok {
+		t.Fatalf("expected points not in G1, got %v, %v", ok, err)
+	}
+
+	// no point and the point at infinity are in G1
+	v = NewBatchVerifier(batchOptions)
+	if ok, err := v.Verify(); err != nil || !ok {
+		t.Fatalf("expected no point in G1, got %v, %v", ok, err)
+	}
+	v = NewBatchVerifier(batchOptions)
+	v.Add(curve.G1Affine{})
+	if ok, err := v.Verify(); err != nil || !ok {
+		t.Fatalf("expected infinity in G1, got %v, %v", ok, err)
+	}
+
+	// Verify is terminal
+	v = NewBatchVerifier(batchOptions)
+	if err := v.AddMany(points); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := v.Verify(); err != nil || !ok {
+		t.Fatalf("expected points in G1, got %v, %v", ok, err)
+	}
+	if err := v.Add(notInG1); !errors.Is(err, ErrVerified) {
+		t.Fatalf("expected ErrVerified, got %v", err)
+	}
+	if err := v.AddMany([]curve.G1Affine{notInG1}); !errors.Is(err, ErrVerified) {
+		t.Fatalf("expected ErrVerified, got %v", err)
+	}
+	if _, err := v.Verify(); !errors.Is(err, ErrVerified) {
+		t.Fatalf("expected ErrVerified, got %v", err)
+	}
+
+	// errors due to the options are reported by Verify
+	for _, tc := range []struct {
+		opts BatchOptions
+		err  error
+	}{
+		{BatchOptions{SecurityLevel: -1}, ErrInvalidSecurityLevel},
+		{BatchOptions{DomainTag: []byte("test")}, ErrStreamingDomainTag},
+		{BatchOptions{Rand: failingReader{}}, errFailingReader},
+	} {
+		v := NewBatchVerifier(tc.opts)
+		v.AddMany(points)
+		if _, err := v.Verify(); !errors.Is(err, tc.err) {
+			t.Fatalf("expected %v, got %v", tc.err, err)
+		}
+	}
+}
+
 // benches
 func BenchmarkIsInSubGroupBatchNaiveShort(b *testing.B) {
 	const nbSamples = 100
@@ -162,7 +657,7 @@ func BenchmarkIsInSubGroupBatchShort(b *testing.B) {
 	result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
 	b.ResetTimer()
 	for j := 0; j < b.N; j++ {
-		IsInSubGroupBatch(result[:], rounds)
+		IsInSubGroupBatch(result[:], batchOptions)
 	}
 }
 
@@ -188,7 +683,7 @@ func BenchmarkComparison(b *testing.B) {
 		b.Run(fmt.Sprintf("%d points", using), func(b *testing.B) {
 			b.ResetTimer()
 			for j := 0; j < b.N; j++ {
-				IsInSubGroupBatch(result[:using], rounds)
+				IsInSubGroupBatch(result[:using], batchOptions)
 			}
 		})
 
@@ -0,0 +1,145 @@
+package bls12381
+
+import (
+	"hash"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// Let h be the cofactor of (E/𝔽p) and let e=3√(h/3).
+// After the Tate pairings test, a point that is not in G1 has a non-trivial
+// component of order dividing e'=e/gcd(π,e) where π= 2⁴·3²·5·7·11·13, whose
+// smallest prime divisor is 10177. The random scalars of a linear combination
+// are drawn uniformly in [0, 2^boundBits) with 2^13 = 8192 < 10177, so they are
+// distinct modulo this prime and such a point survives one combination with
+// probability at most 2⁻¹³.
+//
+// In the Fiat–Shamir mode (see BatchOptions.DomainTag) the bound above holds
+// for one batch, see batch.Transcript for an adversary that can try many.
+const boundBits = 13
+
+// Let h2 be the cofactor of (E'/𝔽p²), the twist that carries G2.
+// h2 = 13²·23²·2713·11953·262069·h2' where h2' is a 448-bit prime. There is no
+// cheap character to filter the small factors out:
+//   - 13 divides p+1 and 23 divides p-1, so Tate pairings of order 13 and 23
+//     are defined over 𝔽p², but their reduced values are 13-th and 23-rd power
+//     residue symbols in 𝔽p², i.e. exponentiations that cost more than the
+//     Scott test,
+//   - 2713, 11953 and 262069 do not divide p²-1, so their Tate pairings are
+//     not even defined over 𝔽p².
+//
+// Hence a point of E'(𝔽p²) that is not in G2 has a non-trivial component of
+// order at least 13. The random scalars of a linear combination are drawn
+// uniformly in [0, 2^boundBitsG2) with 2^3 = 8 < 13, so they are distinct
+// modulo this component and such a point survives one combination with
+// probability at most 2⁻³.
+const boundBitsG2 = 3
+
+const (
+	// DefaultSecurityLevel is the soundness in bits of the batch methods when
+	// BatchOptions.SecurityLevel is zero.
+	DefaultSecurityLevel = 64
+
+	// MaxSecurityLevel is the largest soundness in bits accepted by the batch
+	// methods.
+	MaxSecurityLevel = batch.MaxSecurityLevel
+)
+
+// ErrInvalidSecurityLevel is returned by the batch methods when
+// BatchOptions.SecurityLevel is not in [0, MaxSecurityLevel].
+var ErrInvalidSecurityLevel = batch.ErrInvalidSecurityLevel
+
+// BatchOptions configures the batch subgroup membership methods.
+// The zero value selects the default settings.
+type BatchOptions struct {
+	// SecurityLevel is the target soundness β in bits: a batch that contains
+	// a point not in G1 is accepted with probability at most 2⁻ᵝ. Zero
+	// selects DefaultSecurityLevel.
+	SecurityLevel int
+
+	// Rand is the source of the random scalars. It is only read from the
+	// calling goroutine. If nil, crypto/rand.Reader is used. A seeded
+	// math/rand/v2.ChaCha8 gives reproducible draws.
+	Rand io.Reader
+
+	// DomainTag, if not empty, selects the Fiat–Shamir mode: the random
+	// scalars are derived from a hash of DomainTag and of the serialized
+	// points instead of being read from Rand, so that the decision only
+	// depends on the input and can be replayed, e.g. by every node of a
+	// consensus protocol. DomainTag should be unique to the application.
+	DomainTag []byte
+}
+
+// roundsBits returns, for each random linear combination, the bit size of its
+// random scalars. For a failure probability of 2⁻ᵝ we need rounds=⌈β/13⌉, the
+// last round only needs the remaining bits.
+// For example β=64 gives [13, 13, 13, 13, 12] and β=128 gives rounds=10.
+func (opts *BatchOptions) roundsBits() ([]int, error) {
+	return opts.splitRounds(boundBits)
+}
+
+// roundsBitsG2 is like roundsBits for points of G2, with rounds=⌈β/3⌉.
+// For example β=64 gives 21 rounds of 3 bits and a last round of 1 bit.
+func (opts *BatchOptions) roundsBitsG2() ([]int, error) {
+	return opts.splitRounds(boundBitsG2)
+}
+
+// splitRounds splits the security level of opts in rounds of bound bits.
+func (opts *BatchOptions) splitRounds(bound int) ([]int, error) {
+	securityLevel, err := opts.securityLevel()
+	if err != nil {
+		return nil, err
+	}
+	return batch.SplitRounds(securityLevel, bound), nil
+}
+
+// securityLevel returns the security level β of opts, or an error if it is
+// invalid.
+func (opts *BatchOptions) securityLevel() (int, error) {
+	return batch.SecurityLevel(opts.SecurityLevel, DefaultSecurityLevel)
+}
+
+// randomSources returns n sources of random bytes, one per chunk of a random linear combination,
+// drawn from opts.Rand as batch.RandomSources does.
+func (opts *BatchOptions) randomSources(n int) ([]io.Reader, error) {
+	return batch.RandomSources(opts.Rand, n)
+}
+
+// fiatShamirPrefix and fiatShamirPrefixG2 separate the transcripts of this
+// package from the ones of the other curves and groups.
+const (
+	fiatShamirPrefix   = "batch-subgroup-membership/BLS12-381/G1"
+	fiatShamirPrefixG2 = "batch-subgroup-membership/BLS12-381/G2"
+)
+
+// bindPoints returns opts unchanged, except in the Fiat–Shamir mode where Rand
+// is replaced by the stream of batch.Transcript over the uncompressed
+// encodings of the points, which bind both coordinates, also for points that
+// are not on the curve.
+func (opts BatchOptions) bindPoints(points []curve.G1Affine) BatchOptions {
+	return opts.bindTranscript(fiatShamirPrefix, len(points), func(h hash.Hash, i int) {
+		b := points[i].RawBytes()
+		h.Write(b[:])
+	})
+}
+
+// bindPointsG2 is like bindPoints for points of G2.
+func (opts BatchOptions) bindPointsG2(points []curve.G2Affine) BatchOptions {
+	return opts.bindTranscript(fiatShamirPrefixG2, len(points), func(h hash.Hash, i int) {
+		b := points[i].RawBytes()
+		h.Write(b[:])
+	})
+}
+
+// bindTranscript implements bindPoints for n points, whose raw encodings are
+// written by writePoint.
+func (opts BatchOptions) bindTranscript(prefix string, n int, writePoint func(h hash.Hash, i int)) BatchOptions {
+	if len(opts.DomainTag) == 0 {
+		return opts
+	}
+	securityLevel, _ := opts.securityLevel()
+	opts.Rand = batch.Transcript(prefix, opts.DomainTag, securityLevel, n, writePoint)
+	return opts
+}
@@ -0,0 +1,47 @@
+package bls12381
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/ct"
+)
+
+// The constant-time residue symbols below are meant for secret inputs. They
+// use Euler's criterion with the multiplication of ct.Field, see package ct
+// for why neither a constant-time GCD nor fp.Element.Mul is used.
+
+var (
+	ctField               *ct.Field
+	ctOne, ctMinusOne     fp.Element // 1 and -1
+	cubicExp, legendreExp big.Int    // (p-1)/3 and (p-1)/2
+)
+
+func init() {
+	ctField = ct.NewField(fp.Modulus())
+	ctOne.SetOne()
+	ctMinusOne.Neg(&ctOne)
+	cubicExp.Sub(fp.Modulus(), big.NewInt(1)).Div(&cubicExp, big.NewInt(3))
+	legendreExp.Sub(fp.Modulus(), big.NewInt(1)).Rsh(&legendreExp, 1)
+}
+
+// CubicSymbolConstantTime is like CubicSymbolFast, with a running time that
+// does not depend on x. It returns 0 (symbol=1, cubic residue, or x=0), 1
+// (symbol=ω), or 2 (symbol=ω²).
+func CubicSymbolConstantTime(x fp.Element) uint8 {
+	// x^((p-1)/3) is 0, 1, ω or ω² = -1-ω modulo β
+	var omega2 fp.Element
+	omega2.Sub(&ctMinusOne, &cubOmega)
+	var sym [6]uint64
+	ctField.Exp(&sym, (*[6]uint64)(&x), &cubicExp)
+	return uint8(ct.Equal(&sym, (*[6]uint64)(&cubOmega)) | ct.Equal(&sym, (*[6]uint64)(&omega2))<<1)
+}
+
+// LegendreConstantTime is like fp.Element.Legendre, with a running time that
+// does not depend on x. It returns 1, -1, or 0.
+func LegendreConstantTime(x fp.Element) int {
+	// x^((p-1)/2) is 0, 1 or -1
+	var sym [6]uint64
+	ctField.Exp(&sym, (*[6]uint64)(&x), &legendreExp)
+	return int(ct.Equal(&sym, (*[6]uint64)(&ctOne))) - int(ct.Equal(&sym, (*[6]uint64)(&ctMinusOne)))
+}
@@ -0,0 +1,41 @@
+package bls12381
+
+import (
+	"errors"
+
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// Reasons for which CheckSubGroupBatch rejects a batch. They are wrapped in a
+// *BatchError and can be matched with errors.Is. The reasons that do not
+// depend on the curve are shared with the other curve packages.
+var (
+	// ErrNotOnCurve means that a point is not on the curve.
+	ErrNotOnCurve = batch.ErrNotOnCurve
+
+	// ErrTorsion3 means that a point fails the Tate pairing test of order 3:
+	// it has a non-trivial component of order 3.
+	ErrTorsion3 = batch.ErrTorsion3
+
+	// ErrTorsion11 means that a point fails the Tate pairing test of order 11:
+	// it has a non-trivial component of order 11.
+	ErrTorsion11 = errors.New("point with a non-trivial component of order 11")
+
+	// ErrRandomCombination means that all the points passed the Tate
+	// pairings test but a random linear combination of them is not in G1:
+	// some point has a non-trivial component of order dividing e'.
+	// FindNonMembers locates such points.
+	ErrRandomCombination = batch.ErrRandomCombination
+)
+
+// BatchError is the error returned by CheckSubGroupBatch when a batch is
+// rejected. Its Index is the index of the first offending point, or -1 if it
+// is not known, as for ErrRandomCombination, and its Err is one of
+// ErrNotOnCurve, ErrTorsion3, ErrTorsion11 or ErrRandomCombination.
+type BatchError = batch.Error
+
+// randomCombinationError returns the error of CheckSubGroupBatch for the
+// result of the random linear combinations check.
+func randomCombinationError(ok bool, err error) error {
+	return batch.CombinationError(ok, err, ErrRandomCombination)
+}
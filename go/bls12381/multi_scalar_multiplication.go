@@ -1,10 +1,11 @@
 package bls12381
 
 import (
-	"crypto/rand"
+	"context"
+	"encoding/binary"
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
-	"unsafe"
+	"io"
 )
 
 // g1JacExtended is a point in extended Jacobian coordinates (x=X/ZZ, y=Y/ZZZ, ZZ³=ZZZ²)
@@ -214,10 +215,33 @@ func (p *g1JacExtended) doubleMixed(a *curve.G1Affine) *g1JacExtended {
 // --- MSM ---
 type bucketg1JacExtendedC6 [32]g1JacExtended
 
-func _msmCheck(points []curve.G1Affine) bool {
-	// const nbBitsBounds = 13
-	const c = 6
-	const nbChunks = 3 //(nbBitsBounds + c - 1) / c
+// msmC is the window size of the random linear combinations: the random
+// scalars are split in (msmC-1)-bit digits, one per chunk.
+const msmC = 6
+
+// msmNbChunks returns the number of chunks of nbBits-bit random scalars.
+func msmNbChunks(nbBits int) int {
+	return (nbBits + msmC - 2) / (msmC - 1)
+}
+
+// _msmCheck checks that S=∑[s_i]P_i is on E[r] for random scalars s_i in
+// [0, 2^nbBits). The digits of the chunk j are read from sources[j].
+// It returns ctx.Err() if ctx is done before S is computed.
+func _msmCheck(ctx context.Context, points []curve.G1Affine, nbBits int, sources []io.Reader) (bool, error) {
+	p := msmRandomCombination(ctx, points, nbBits, sources)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return p.IsInSubGroup(), nil
+}
+
+// msmRandomCombination returns ∑[s_i]P_i for random scalars s_i in
+// [0, 2^nbBits). The digits of the chunk j are read from sources[j], with
+// len(sources) == msmNbChunks(nbBits). If ctx is done the chunks stop early
+// and the result is meaningless.
+func msmRandomCombination(ctx context.Context, points []curve.G1Affine, nbBits int, sources []io.Reader) *curve.G1Jac {
+	const c = msmC
+	nbChunks := msmNbChunks(nbBits)
 
 	// for each chunk, spawn one go routine that'll loop through all the scalars in the
 	// corresponding bit-window
@@ -229,28 +253,29 @@ func _msmCheck(points []curve.G1Affine) bool {
 		chChunks[i] = make(chan g1JacExtended, 1)
 	}
 
-	for j := int(nbChunks - 1); j >= 0; j-- {
-		go processChunkG1Simplified[bucketg1JacExtendedC6](uint64(j), chChunks[j], c, points)
+	for j := nbChunks - 1; j >= 0; j-- {
+		// the most significant digit may be shorter
+		digitBits := min(c-1, nbBits-j*(c-1))
+		go processChunkG1Simplified[bucketg1JacExtendedC6](ctx, uint64(j), chChunks[j], uint64(digitBits), points, sources[j])
 	}
 
-	p := msmReduceChunkG1Affine(int(c), chChunks[:])
-
-	return p.IsInSubGroup()
+	return msmReduceChunkG1Affine(c-1, chChunks[:])
 }
 
-func processChunkG1Simplified[B bucketg1JacExtendedC6](chunk uint64,
+// processChunkG1Simplified computes ∑[d_i]P_i for random digits d_i in
+// [0, 2^digitBits) read from rng, with digitBits ≤ 5, using the buckets
+// method. It stops early if ctx is done.
+func processChunkG1Simplified[B bucketg1JacExtendedC6](ctx context.Context, chunk uint64,
 	chRes chan<- g1JacExtended,
-	c uint64,
-	points []curve.G1Affine) {
+	digitBits uint64,
+	points []curve.G1Affine,
+	rng io.Reader) {
 
 	const windowSize = 1024
 	var br [windowSize * 2]byte
 
-	// interpret br as an array of uint16 of size windowSize/2
-	randomScalars := (*[windowSize]uint16)(unsafe.Pointer(&br[0]))
-
-	// we need a mask to get only the (c-1) lowest bits of each scalar
-	mask := uint16((1 << (c - 1)) - 1)
+	// we need a mask to get only the digitBits lowest bits of each scalar
+	mask := uint16((1 << digitBits) - 1)
 
 	var buckets B
 	for i := 0; i < len(buckets); i++ {
@@ -260,19 +285,27 @@ func processChunkG1Simplified[B bucketg1JacExtendedC6](chunk uint64,
 	// for each scalars, get the digit corresponding to the chunk we're processing.
 	for i := range points {
 		if i%windowSize == 0 {
+			if ctx.Err() != nil {
+				break
+			}
 			// fill the lowest c bits of each scalar with random bytes
-			rand.Read(br[:]) // does not return an error, always fills br
+			rng.Read(br[:]) // crypto/rand and ChaCha8 do not return an error, always fill br
 		}
-		digit := randomScalars[i%windowSize] & mask
+		// br is read as little-endian uint16 so that the digits drawn from a
+		// seeded source do not depend on the platform
+		digit := binary.LittleEndian.Uint16(br[2*(i%windowSize):]) & mask
 		if digit == 0 {
 			continue
 		}
 		buckets[digit-1].addMixed(&points[i])
 	}
 
-	// reduce buckets into total
-	// total =  bucket[0] + 2*bucket[1] + 3*bucket[2] ... + n*bucket[n-1]
+	chRes <- reduceBuckets(buckets[:])
+}
 
+// reduceBuckets returns the weighted sum of the buckets
+// total =  bucket[0] + 2*bucket[1] + 3*bucket[2] ... + n*bucket[n-1]
+func reduceBuckets(buckets []g1JacExtended) g1JacExtended {
 	var runningSum, total g1JacExtended
 	runningSum.SetInfinity()
 	total.SetInfinity()
@@ -282,8 +315,7 @@ func processChunkG1Simplified[B bucketg1JacExtendedC6](chunk uint64,
 		}
 		total.add(&runningSum)
 	}
-
-	chRes <- total
+	return total
 }
 
 // msmReduceChunkG1Affine reduces the weighted sum of the buckets into the result of the multiExp
@@ -0,0 +1,325 @@
+package bls12381
+
+import (
+	"context"
+	"encoding/binary"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"io"
+)
+
+// g2JacExtended is a point in extended Jacobian coordinates (x=X/ZZ, y=Y/ZZZ, ZZ³=ZZZ²)
+type g2JacExtended struct {
+	X, Y, ZZ, ZZZ curve.E2
+}
+
+// Set sets p to a in extended Jacobian coordinates.
+func (p *g2JacExtended) Set(q *g2JacExtended) *g2JacExtended {
+	p.X, p.Y, p.ZZ, p.ZZZ = q.X, q.Y, q.ZZ, q.ZZZ
+	return p
+}
+
+// SetInfinity sets p to the infinity point (1,1,0,0).
+func (p *g2JacExtended) SetInfinity() *g2JacExtended {
+	p.X.SetOne()
+	p.Y.SetOne()
+	p.ZZ = curve.E2{}
+	p.ZZZ = curve.E2{}
+	return p
+}
+
+// IsInfinity checks if the p is infinity, i.e. p.ZZ=0.
+func (p *g2JacExtended) IsInfinity() bool {
+	return p.ZZ.IsZero()
+}
+
+// unsafeFromJacExtendedG2 converts an extended Jacobian point, distinct from Infinity, to a Jacobian point.
+func unsafeFromJacExtendedG2(q *g2JacExtended) *curve.G2Jac {
+	var p curve.G2Jac
+	p.X.Square(&q.ZZ).Mul(&p.X, &q.X)
+	p.Y.Square(&q.ZZZ).Mul(&p.Y, &q.Y)
+	p.Z = q.ZZZ
+	return &p
+}
+
+// add sets p to p+q in extended Jacobian coordinates.
+//
+// https://www.hyperelliptic.org/EFD/g1p/auto-shortw-xyzz.html#addition-add-2008-s
+func (p *g2JacExtended) add(q *g2JacExtended) *g2JacExtended {
+	//if q is infinity return p
+	if q.ZZ.IsZero() {
+		return p
+	}
+	// p is infinity, return q
+	if p.ZZ.IsZero() {
+		p.Set(q)
+		return p
+	}
+
+	var A, B, U1, U2, S1, S2 curve.E2
+
+	// p2: q, p1: p
+	U2.Mul(&q.X, &p.ZZ)
+	U1.Mul(&p.X, &q.ZZ)
+	A.Sub(&U2, &U1)
+	S2.Mul(&q.Y, &p.ZZZ)
+	S1.Mul(&p.Y, &q.ZZZ)
+	B.Sub(&S2, &S1)
+
+	if A.IsZero() {
+		if B.IsZero() {
+			return p.double(q)
+
+		}
+		p.ZZ = curve.E2{}
+		p.ZZZ = curve.E2{}
+		return p
+	}
+
+	var P, R, PP, PPP, Q, V curve.E2
+	P.Sub(&U2, &U1)
+	R.Sub(&S2, &S1)
+	PP.Square(&P)
+	PPP.Mul(&P, &PP)
+	Q.Mul(&U1, &PP)
+	V.Mul(&S1, &PPP)
+
+	p.X.Square(&R).
+		Sub(&p.X, &PPP).
+		Sub(&p.X, &Q).
+		Sub(&p.X, &Q)
+	p.Y.Sub(&Q, &p.X).
+		Mul(&p.Y, &R).
+		Sub(&p.Y, &V)
+	p.ZZ.Mul(&p.ZZ, &q.ZZ).
+		Mul(&p.ZZ, &PP)
+	p.ZZZ.Mul(&p.ZZZ, &q.ZZZ).
+		Mul(&p.ZZZ, &PPP)
+
+	return p
+}
+
+// double sets p to [2]q in Jacobian extended coordinates.
+//
+// http://www.hyperelliptic.org/EFD/g1p/auto-shortw-xyzz.html#doubling-dbl-2008-s-1
+// N.B.: since we consider any point on Z=0 as the point at infinity
+// this doubling formula works for infinity points as well.
+func (p *g2JacExtended) double(q *g2JacExtended) *g2JacExtended {
+	var U, V, W, S, XX, M curve.E2
+
+	U.Double(&q.Y)
+	V.Square(&U)
+	W.Mul(&U, &V)
+	S.Mul(&q.X, &V)
+	XX.Square(&q.X)
+	M.Double(&XX).
+		Add(&M, &XX) // -> + A, but A=0 here
+	U.Mul(&W, &q.Y)
+
+	p.X.Square(&M).
+		Sub(&p.X, &S).
+		Sub(&p.X, &S)
+	p.Y.Sub(&S, &p.X).
+		Mul(&p.Y, &M).
+		Sub(&p.Y, &U)
+	p.ZZ.Mul(&V, &q.ZZ)
+	p.ZZZ.Mul(&W, &q.ZZZ)
+
+	return p
+}
+
+// addMixed sets p to p+q in extended Jacobian coordinates, where a.ZZ=1.
+//
+// http://www.hyperelliptic.org/EFD/g1p/auto-shortw-xyzz.html#addition-madd-2008-s
+func (p *g2JacExtended) addMixed(a *curve.G2Affine) *g2JacExtended {
+
+	//if a is infinity return p
+	if a.IsInfinity() {
+		return p
+	}
+	// p is infinity, return a
+	if p.ZZ.IsZero() {
+		p.X = a.X
+		p.Y = a.Y
+		p.ZZ.SetOne()
+		p.ZZZ.SetOne()
+		return p
+	}
+
+	var P, R curve.E2
+
+	// p2: a, p1: p
+	P.Mul(&a.X, &p.ZZ)
+	P.Sub(&P, &p.X)
+
+	R.Mul(&a.Y, &p.ZZZ)
+	R.Sub(&R, &p.Y)
+
+	if P.IsZero() {
+		if R.IsZero() {
+			return p.doubleMixed(a)
+
+		}
+		p.ZZ = curve.E2{}
+		p.ZZZ = curve.E2{}
+		return p
+	}
+
+	var PP, PPP, Q, Q2, RR, X3, Y3 curve.E2
+
+	PP.Square(&P)
+	PPP.Mul(&P, &PP)
+	Q.Mul(&p.X, &PP)
+	RR.Square(&R)
+	X3.Sub(&RR, &PPP)
+	Q2.Double(&Q)
+	p.X.Sub(&X3, &Q2)
+	Y3.Sub(&Q, &p.X).Mul(&Y3, &R)
+	R.Mul(&p.Y, &PPP)
+	p.Y.Sub(&Y3, &R)
+	p.ZZ.Mul(&p.ZZ, &PP)
+	p.ZZZ.Mul(&p.ZZZ, &PPP)
+
+	return p
+
+}
+
+// doubleMixed sets p to [2]a in Jacobian extended coordinates, where a.ZZ=1.
+//
+// http://www.hyperelliptic.org/EFD/g1p/auto-shortw-xyzz.html#doubling-dbl-2008-s-1
+func (p *g2JacExtended) doubleMixed(a *curve.G2Affine) *g2JacExtended {
+
+	var U, V, W, S, XX, M, S2, L curve.E2
+
+	U.Double(&a.Y)
+	V.Square(&U)
+	W.Mul(&U, &V)
+	S.Mul(&a.X, &V)
+	XX.Square(&a.X)
+	M.Double(&XX).
+		Add(&M, &XX) // -> + A, but A=0 here
+	S2.Double(&S)
+	L.Mul(&W, &a.Y)
+
+	p.X.Square(&M).
+		Sub(&p.X, &S2)
+	p.Y.Sub(&S, &p.X).
+		Mul(&p.Y, &M).
+		Sub(&p.Y, &L)
+	p.ZZ.Set(&V)
+	p.ZZZ.Set(&W)
+
+	return p
+}
+
+// --- MSM ---
+type bucketg2JacExtendedC6 [32]g2JacExtended
+
+// _msmCheckG2 checks that S=∑[s_i]Q_i is in G2 for random scalars s_i in
+// [0, 2^nbBits). The digits of the chunk j are read from sources[j].
+// It returns ctx.Err() if ctx is done before S is computed.
+func _msmCheckG2(ctx context.Context, points []curve.G2Affine, nbBits int, sources []io.Reader) (bool, error) {
+	p := msmRandomCombinationG2(ctx, points, nbBits, sources)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return p.IsInSubGroup(), nil
+}
+
+// msmRandomCombinationG2 returns ∑[s_i]Q_i for random scalars s_i in
+// [0, 2^nbBits). The digits of the chunk j are read from sources[j], with
+// len(sources) == msmNbChunks(nbBits). If ctx is done the chunks stop early
+// and the result is meaningless.
+func msmRandomCombinationG2(ctx context.Context, points []curve.G2Affine, nbBits int, sources []io.Reader) *curve.G2Jac {
+	const c = msmC
+	nbChunks := msmNbChunks(nbBits)
+
+	// for each chunk, spawn one go routine that'll loop through all the scalars in the
+	// corresponding bit-window
+	// note that buckets is an array allocated on the stack and this is critical for performance
+
+	// each go routine sends its result in chChunks[i] channel
+	chChunks := make([]chan g2JacExtended, nbChunks)
+	for i := 0; i < len(chChunks); i++ {
+		chChunks[i] = make(chan g2JacExtended, 1)
+	}
+
+	for j := nbChunks - 1; j >= 0; j-- {
+		// the most significant digit may be shorter
+		digitBits := min(c-1, nbBits-j*(c-1))
+		go processChunkG2Simplified[bucketg2JacExtendedC6](ctx, uint64(j), chChunks[j], uint64(digitBits), points, sources[j])
+	}
+
+	return msmReduceChunkG2Affine(c-1, chChunks[:])
+}
+
+// processChunkG2Simplified computes ∑[d_i]Q_i for random digits d_i in
+// [0, 2^digitBits) read from rng, with digitBits ≤ 5, using the buckets
+// method. It stops early if ctx is done.
+func processChunkG2Simplified[B bucketg2JacExtendedC6](ctx context.Context, chunk uint64,
+	chRes chan<- g2JacExtended,
+	digitBits uint64,
+	points []curve.G2Affine,
+	rng io.Reader) {
+
+	const windowSize = 1024
+	var br [windowSize * 2]byte
+
+	// we need a mask to get only the digitBits lowest bits of each scalar
+	mask := uint16((1 << digitBits) - 1)
+
+	var buckets B
+	for i := 0; i < len(buckets); i++ {
+		buckets[i].SetInfinity()
+	}
+
+	// for each scalars, get the digit corresponding to the chunk we're processing.
+	for i := range points {
+		if i%windowSize == 0 {
+			if ctx.Err() != nil {
+				break
+			}
+			// fill the lowest c bits of each scalar with random bytes
+			rng.Read(br[:]) // crypto/rand and ChaCha8 do not return an error, always fill br
+		}
+		// br is read as little-endian uint16 so that the digits drawn from a
+		// seeded source do not depend on the platform
+		digit := binary.LittleEndian.Uint16(br[2*(i%windowSize):]) & mask
+		if digit == 0 {
+			continue
+		}
+		buckets[digit-1].addMixed(&points[i])
+	}
+
+	chRes <- reduceBucketsG2(buckets[:])
+}
+
+// reduceBucketsG2 returns the weighted sum of the buckets
+// total =  bucket[0] + 2*bucket[1] + 3*bucket[2] ... + n*bucket[n-1]
+func reduceBucketsG2(buckets []g2JacExtended) g2JacExtended {
+	var runningSum, total g2JacExtended
+	runningSum.SetInfinity()
+	total.SetInfinity()
+	for k := len(buckets) - 1; k >= 0; k-- {
+		if !buckets[k].IsInfinity() {
+			runningSum.add(&buckets[k])
+		}
+		total.add(&runningSum)
+	}
+	return total
+}
+
+// msmReduceChunkG2Affine reduces the weighted sum of the buckets into the result of the multiExp
+func msmReduceChunkG2Affine(c int, chChunks []chan g2JacExtended) *curve.G2Jac {
+	var _p g2JacExtended
+	totalj := <-chChunks[len(chChunks)-1]
+	_p.Set(&totalj)
+	for j := len(chChunks) - 2; j >= 0; j-- {
+		for l := 0; l < c; l++ {
+			_p.double(&_p)
+		}
+		totalj := <-chChunks[j]
+		_p.add(&totalj)
+	}
+
+	return unsafeFromJacExtendedG2(&_p)
+}
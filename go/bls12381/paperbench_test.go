@@ -1,6 +1,7 @@
 package bls12381
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -28,13 +29,43 @@ func BenchmarkPaperComparison(b *testing.B) {
 		b.Run(fmt.Sprintf("%d points", using), func(b *testing.B) {
 			b.ResetTimer()
 			for j := 0; j < b.N; j++ {
-				IsInSubGroupBatch(result[:using], rounds)
+				IsInSubGroupBatch(result[:using], batchOptions)
 			}
 		})
 		b.Run(fmt.Sprintf("%d points-step2", using), func(b *testing.B) {
+			sources, _ := batchOptions.randomSources(msmNbChunks(boundBits))
 			b.ResetTimer()
 			for j := 0; j < b.N; j++ {
-				_msmCheck(result[:using])
+				_msmCheck(context.Background(), result[:using], boundBits, sources)
+			}
+		})
+	}
+}
+
+func BenchmarkPaperComparisonG2(b *testing.B) {
+	// G2 points are twice as large as G1 points, so the largest batch sizes
+	// are skipped.
+	const nbSamples = 1 << 17
+	var sampleScalars [nbSamples]fr.Element
+	fillBenchScalars(sampleScalars[:])
+
+	_, _, _, g := curve.Generators()
+	result := curve.BatchScalarMultiplicationG2(&g, sampleScalars[:])
+
+	for _, using := range paperBenchSizes {
+		if using > nbSamples {
+			break
+		}
+		b.Run(fmt.Sprintf("%d points-naive", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchNaiveG2(result[:using])
+			}
+		})
+		b.Run(fmt.Sprintf("%d points", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchG2(result[:using], batchOptions)
 			}
 		})
 	}
@@ -1,6 +1,10 @@
 package bls12381
 
 import (
+	"context"
+	"io"
+	"sort"
+	"sync"
 	"sync/atomic"
 
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
@@ -35,71 +39,305 @@ func IsInSubGroupBatchNaiveParallel(points []curve.G1Affine) bool {
 }
 
 // IsInSubGroupBatch checks if a batch of points P_i are in G1.
-// First, it checks that all points are on a larger torsion E[r*e'] using Tate
-// pairings [Koshelev22].
-// Second, it generates random scalars s_i in the range [0, bound), performs
-// n=rounds multi-scalar-multiplication Sj=∑[s_i]P_i of sizes N=len(points) and
-// checks if Sj are on E[r] using Scott test [Scott21].
+// First, it checks that all points are on the curve and on a larger torsion
+// E[r*e'] using Tate pairings [Koshelev22]. The point at infinity, which is in
+// G1, skips the pairings.
+// Second, it generates random scalars s_i in the range [0, 2^13), performs
+// n=⌈β/13⌉ multi-scalar-multiplication Sj=∑[s_i]P_i of sizes N=len(points) and
+// checks if Sj are on E[r] using Scott test [Scott21], where β is the security
+// level of opts.
+//
+// Points in Jacobian coordinates can be converted with curve.BatchJacobianToAffineG1
+// first: its batch inversion costs less than 1% of the check.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
 //
 // [Koshelev22]: https://eprint.iacr.org/2022/037.pdf
 // [Scott21]: https://eprint.iacr.org/2021/1130.pdf
-func IsInSubGroupBatch(points []curve.G1Affine, rounds int) bool {
+func IsInSubGroupBatch(points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchContext(context.Background(), points, opts)
+}
 
-	// 1. Check points are on E[r*e']
+// IsInSubGroupBatchContext is like IsInSubGroupBatch but gives up when ctx is
+// done: the Tate pairings loop and the multi-scalar-multiplication goroutines
+// stop promptly and ctx.Err() is returned.
+func IsInSubGroupBatchContext(ctx context.Context, points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve and on E[r*e']
 	for i := range points {
-		// 1.1. Tate_{3,P3}(Q) = (y-2)^((p-1)/3) == 1, with P3 = (0,2).
-		if !isFirstTateOne(points[i]) {
-			return false
+		if err := ctx.Err(); err != nil {
+			return false, err
 		}
-		// 1.2. Tate_{11,P11}(Q) == 1
-		if !isSecondTateOne(points[i]) {
-			return false
+		if checkPoint(&points[i]) != nil {
+			return false, nil
 		}
 	}
 
 	// 2. Check Sj are on E[r]
-	const nbRounds = 5
-	for i := 0; i < nbRounds; i++ {
-		if !_msmCheck(points) {
-			return false
-		}
-	}
+	opts = opts.bindPoints(points)
+	return msmCheckRounds(ctx, points, roundsBits, &opts)
+}
 
-	return true
+func IsInSubGroupBatchParallel(points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchParallelContext(context.Background(), points, opts)
 }
 
-func IsInSubGroupBatchParallel(points []curve.G1Affine, rounds int) bool {
+// IsInSubGroupBatchParallelContext is like IsInSubGroupBatchParallel but gives
+// up when ctx is done, see IsInSubGroupBatchContext.
+func IsInSubGroupBatchParallelContext(ctx context.Context, points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return false, err
+	}
 
-	// 1. Check points are on E[r*e']
+	// 1. Check points are on the curve and on E[r*e']
 	var nbErrors int64
-	parallel.Execute(len(points), func(start, end int) {
+	err = parallel.ExecuteContext(ctx, len(points), func(start, end int) {
 		for i := start; i < end; i++ {
-			// 1.1. Tate_{3,P3}(Q) = (y-2)^((p-1)/3) == 1, with P3 = (0,2).
-			if !isFirstTateOne(points[i]) {
-				atomic.AddInt64(&nbErrors, 1)
+			if ctx.Err() != nil {
 				return
 			}
-			// 1.2. Tate_{11,P11}(Q) == 1
-			if !isSecondTateOne(points[i]) {
+			if checkPoint(&points[i]) != nil {
 				atomic.AddInt64(&nbErrors, 1)
 				return
 			}
 		}
 	})
+	if err != nil {
+		return false, err
+	}
 	if nbErrors > 0 {
-		return false
+		return false, nil
 	}
 
 	// 2. Check Sj are on E[r]
-	const nbRounds = 5
-	parallel.Execute(nbRounds, func(start, end int) {
+	opts = opts.bindPoints(points)
+	return msmCheckRoundsParallel(ctx, points, roundsBits, &opts)
+}
+
+// CheckSubGroupBatch is like IsInSubGroupBatch but explains why a batch is
+// rejected. It returns nil if all the points are in G1 and otherwise a
+// *BatchError wrapping:
+//   - ErrNotOnCurve if a point is not on the curve,
+//   - ErrTorsion3 or ErrTorsion11 if a point fails the Tate pairings test,
+//     e.g. ErrTorsion3 for the point (0,2) of order 3,
+//   - ErrRandomCombination if a random linear combination is not in G1.
+//
+// Any other error comes from opts, as for IsInSubGroupBatch.
+func CheckSubGroupBatch(points []curve.G1Affine, opts BatchOptions) error {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return err
+	}
+
+	// 1. Check points are on the curve and on E[r*e']
+	for i := range points {
+		if err := checkPoint(&points[i]); err != nil {
+			return &BatchError{Index: i, Err: err}
+		}
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return randomCombinationError(msmCheckRounds(context.Background(), points, roundsBits, &opts))
+}
+
+func CheckSubGroupBatchParallel(points []curve.G1Affine, opts BatchOptions) error {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return err
+	}
+
+	// 1. Check points are on the curve and on E[r*e']
+	// each worker stops at its first offending point, so the smallest one
+	// reported is the first offending point of the batch.
+	var lock sync.Mutex
+	var firstErr *BatchError
+	parallel.Execute(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if err := checkPoint(&points[i]); err != nil {
+				lock.Lock()
+				if firstErr == nil || i < firstErr.Index {
+					firstErr = &BatchError{Index: i, Err: err}
+				}
+				lock.Unlock()
+				return
+			}
+		}
+	})
+	if firstErr != nil {
+		return firstErr
+	}
+
+	// 2. Check Sj are on E[r]
+	opts = opts.bindPoints(points)
+	return randomCombinationError(msmCheckRoundsParallel(context.Background(), points, roundsBits, &opts))
+}
+
+// checkPoint returns the reason why p is not on the curve or not on E[r*e'],
+// or nil.
+func checkPoint(p *curve.G1Affine) error {
+	// the point at infinity is in G1, and its encoding (0,0) is not a valid
+	// input to the Tate pairings.
+	if p.IsInfinity() {
+		return nil
+	}
+	if !p.IsOnCurve() {
+		return ErrNotOnCurve
+	}
+	// Tate_{3,P3}(Q) = (y-2)^((p-1)/3) == 1, with P3 = (0,2). For Q = P3 the
+	// function y-2 vanishes and its cubic symbol is 0, not 1.
+	if p.Y.Equal(&two_p) || !isFirstTateOne(*p) {
+		return ErrTorsion3
+	}
+	// Tate_{11,P11}(Q) == 1
+	if !isSecondTateOne(*p) {
+		return ErrTorsion11
+	}
+	return nil
+}
+
+// FindNonMembers returns the indices, in increasing order, of the points P_i
+// that are not in G1.
+// First, every point that is not on the curve or fails the Tate pairings test
+// of IsInSubGroupBatch is reported directly.
+// Second, the remaining points are bisected using the random linear
+// combinations check of IsInSubGroupBatch: a half that passes is discarded and
+// a half that fails is split again, down to single points that are checked
+// with Scott test [Scott21]. For k culprits among N points this costs
+// O(k·log(N)) multi-scalar-multiplications instead of N scalar
+// multiplications.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func FindNonMembers(points []curve.G1Affine, opts BatchOptions) ([]int, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return nil, err
+	}
+
+	var nonMembers []int
+
+	// 1. Points that are not on the curve or not on E[r*e'] are not in G1
+	candidates := points
+	var indices []int // indices[k] is the index of candidates[k] in points, nil for the identity
+	for i := range points {
+		if checkPoint(&points[i]) == nil {
+			if indices != nil {
+				candidates = append(candidates, points[i])
+				indices = append(indices, i)
+			}
+			continue
+		}
+		if indices == nil {
+			// first culprit: from now on keep track of the remaining points
+			candidates = make([]curve.G1Affine, i, len(points))
+			copy(candidates, points[:i])
+			indices = make([]int, i, len(points))
+			for k := range indices {
+				indices[k] = k
+			}
+		}
+		nonMembers = append(nonMembers, i)
+	}
+
+	// 2. Bisect the points on E[r*e'] using the random linear combinations check
+	opts = opts.bindPoints(points)
+	culprits, err := bisectNonMembers(context.Background(), candidates, 0, false, roundsBits, &opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range culprits {
+		if indices != nil {
+			k = indices[k]
+		}
+		nonMembers = append(nonMembers, k)
+	}
+	sort.Ints(nonMembers)
+
+	return nonMembers, nil
+}
+
+// bisectNonMembers appends to culprits the offsets, shifted by offset, of the
+// points that are not in G1. If knownBad is set, points is already known to
+// fail the random linear combinations check and it is not run again.
+func bisectNonMembers(ctx context.Context, points []curve.G1Affine, offset int, knownBad bool, roundsBits []int, opts *BatchOptions, culprits []int) ([]int, error) {
+	if len(points) == 0 {
+		return culprits, nil
+	}
+	if len(points) == 1 {
+		// a single point is checked exactly with Scott test.
+		if !points[0].IsInSubGroup() {
+			culprits = append(culprits, offset)
+		}
+		return culprits, nil
+	}
+	if !knownBad {
+		ok, err := msmCheckRounds(ctx, points, roundsBits, opts)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return culprits, nil
+		}
+	}
+
+	// if the left half passes, the culprits are in the right half.
+	mid := len(points) / 2
+	n := len(culprits)
+	culprits, err := bisectNonMembers(ctx, points[:mid], offset, false, roundsBits, opts, culprits)
+	if err != nil {
+		return nil, err
+	}
+	return bisectNonMembers(ctx, points[mid:], offset+mid, len(culprits) == n, roundsBits, opts, culprits)
+}
+
+// msmCheckRounds checks that the random linear combinations Sj=∑[s_i]P_i are on
+// E[r], where the scalars of Sj are drawn in [0, 2^roundsBits[j]) from
+// opts.Rand.
+func msmCheckRounds(ctx context.Context, points []curve.G1Affine, roundsBits []int, opts *BatchOptions) (bool, error) {
+	for _, nbBits := range roundsBits {
+		sources, err := opts.randomSources(msmNbChunks(nbBits))
+		if err != nil {
+			return false, err
+		}
+		ok, err := _msmCheck(ctx, points, nbBits, sources)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// msmCheckRoundsParallel is like msmCheckRounds but checks the random linear
+// combinations in parallel.
+func msmCheckRoundsParallel(ctx context.Context, points []curve.G1Affine, roundsBits []int, opts *BatchOptions) (bool, error) {
+	// opts.Rand is only read from the calling goroutine, so the random sources
+	// of all the rounds are drawn beforehand.
+	sources := make([][]io.Reader, len(roundsBits))
+	for i, nbBits := range roundsBits {
+		var err error
+		if sources[i], err = opts.randomSources(msmNbChunks(nbBits)); err != nil {
+			return false, err
+		}
+	}
+	var nbErrors int64
+	err := parallel.ExecuteContext(ctx, len(roundsBits), func(start, end int) {
 		for i := start; i < end; i++ {
-			if !_msmCheck(points) {
+			if ok, _ := _msmCheck(ctx, points, roundsBits[i], sources[i]); !ok {
 				atomic.AddInt64(&nbErrors, 1)
 				return
 			}
 		}
 	})
+	if err != nil {
+		return false, err
+	}
 
-	return nbErrors == 0
+	return nbErrors == 0, nil
 }
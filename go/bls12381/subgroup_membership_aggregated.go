@@ -0,0 +1,151 @@
+package bls12381
+
+import (
+	"context"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/yelhousni/batch-subgroup-membership/go/internal/batch"
+)
+
+// IsInSubGroupBatchAggregated is like IsInSubGroupBatch but aggregates the
+// Tate pairings test of the whole batch instead of running it point by point.
+//
+// The Tate pairings of a point are the characters χ₃(y-2), χ₁₁(f_{11,P11}) and
+// χ₁₁(f_{11,P'11}), where χ₃ is the cubic residue symbol and χ₁₁ the 11-th power
+// residue symbol (see isSecondTateOne). They are multiplicative, so each round
+// checks that
+//
+//	χ₃(∏(y_i-2)^{c_i}) == 1 and χ₁₁(∏f_{11,P11}(Q_i)^{a_i}·f_{11,P'11}(Q_i)^{b_i}) == 1
+//
+// for random c_i ∈ {0,1,2} and a_i, b_i ∈ {0,1}. The products are accumulated
+// in a single pass over the points, the numerators and denominators of the
+// Miller functions separately so that no inversion is needed. Every point is
+// multiplied into the ⌈β/log₂(3)⌉ products of order 3 and the β products of
+// order 11, so that the test costs O(N·β) multiplications and O(β) symbols
+// instead of 3N symbols. If a
+// point fails the Tate pairings test, the exponent of one of its non-trivial
+// characters takes at most one value out of 3, resp. 2, for which the product
+// is a residue, so a round misses it with probability at most 1/3, resp. 1/2.
+// With ⌈β/log₂(3)⌉ rounds of order 3 and β rounds of order 11 such a batch
+// passes with probability at most 2⁻ᵝ. The exponents and the scalars of the
+// random linear combinations are independent, so a batch that contains a
+// point not in G1 is still accepted with probability at most 2⁻ᵝ.
+//
+// A zero numerator or denominator is rejected directly, as by the final
+// exponentiation of IsInSubGroupBatch, and so is a zero factor y-2, as by
+// checkPoint: the point (0,2) of order 3 is not in G1.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+func IsInSubGroupBatchAggregated(points []curve.G1Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBits()
+	if err != nil {
+		return false, err
+	}
+	rounds3, rounds11, err := opts.tateRounds()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	for i := range points {
+		if !points[i].IsInfinity() && !points[i].IsOnCurve() {
+			return false, nil
+		}
+	}
+
+	// 2. Check points are on E[r*e'] with aggregated Tate pairings
+	opts = opts.bindPoints(points)
+	sources, err := opts.randomSources(1)
+	if err != nil {
+		return false, err
+	}
+	if !isTateOneAggregated(points, rounds3, rounds11, sources[0]) {
+		return false, nil
+	}
+
+	// 3. Check Sj are on E[r]
+	return msmCheckRounds(context.Background(), points, roundsBits, &opts)
+}
+
+// tateRounds returns the number of rounds of the aggregated Tate pairings test
+// for the characters of order 3 and 11, see IsInSubGroupBatchAggregated.
+func (opts *BatchOptions) tateRounds() (rounds3, rounds11 int, err error) {
+	securityLevel, err := opts.securityLevel()
+	if err != nil {
+		return 0, 0, err
+	}
+	rounds11, rounds3 = batch.TateRounds(securityLevel)
+	return rounds3, rounds11, nil
+}
+
+// isTateOneAggregated checks that all the points pass the Tate pairings test
+// of checkPoint, except with probability 2⁻ᵝ, using rounds3 and rounds11 random
+// products drawn from rng. The points must be on the curve.
+func isTateOneAggregated(points []curve.G1Affine, rounds3, rounds11 int, rng io.Reader) bool {
+	// running products, one per round
+	acc3 := make([]fp.Element, rounds3)
+	acc11Num := make([]fp.Element, rounds11)
+	acc11Denom := make([]fp.Element, rounds11)
+	for i := range acc3 {
+		acc3[i].SetOne()
+	}
+	for i := range acc11Num {
+		acc11Num[i].SetOne()
+		acc11Denom[i].SetOne()
+	}
+
+	exps := batch.NewExponentReader(rng)
+	var tate3, tate3Sq fp.Element
+	for i := range points {
+		// the point at infinity is in G1.
+		if points[i].IsInfinity() {
+			continue
+		}
+		num1, denom1 := tateP11Fraction(&points[i], &lines1)
+		num2, denom2 := tateP11Fraction(&points[i], &lines2)
+		if num1.IsZero() || denom1.IsZero() || num2.IsZero() || denom2.IsZero() {
+			return false
+		}
+
+		for j := range acc11Num {
+			e := exps.Bits2()
+			if e&1 != 0 {
+				acc11Num[j].Mul(&acc11Num[j], &num1)
+				acc11Denom[j].Mul(&acc11Denom[j], &denom1)
+			}
+			if e&2 != 0 {
+				acc11Num[j].Mul(&acc11Num[j], &num2)
+				acc11Denom[j].Mul(&acc11Denom[j], &denom2)
+			}
+		}
+		// y-2=0 would leave the products unchanged, as CubicSymbolFast(0)
+		// is 0, but (0,2) has order 3.
+		tate3.Sub(&points[i].Y, &two_p)
+		if tate3.IsZero() {
+			return false
+		}
+		tate3Sq.Square(&tate3)
+		for j := range acc3 {
+			switch exps.Trit() {
+			case 1:
+				acc3[j].Mul(&acc3[j], &tate3)
+			case 2:
+				acc3[j].Mul(&acc3[j], &tate3Sq)
+			}
+		}
+	}
+
+	for j := range acc3 {
+		if !IsCubicResidueFast(&acc3[j]) {
+			return false
+		}
+	}
+	for j := range acc11Num {
+		if !tateP11Reduce(&acc11Num[j], &acc11Denom[j]).IsOne() {
+			return false
+		}
+	}
+	return true
+}
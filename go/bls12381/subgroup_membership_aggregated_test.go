@@ -0,0 +1,180 @@
+package bls12381
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestIsInSubGroupBatchAggregated(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = 20
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	// number of points to test
+	const nbSamples = 100
+
+	// random points in G1
+	sample := func(mixer fr.Element) []curve.G1Affine {
+		// mixer ensures that all the words of a frElement are set
+		var sampleScalars [nbSamples]fr.Element
+		for i := 1; i <= nbSamples; i++ {
+			sampleScalars[i-1].SetUint64(uint64(i)).
+				Mul(&sampleScalars[i-1], &mixer)
+		}
+		_, _, g, _ := curve.Generators()
+		return curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
+	}
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchAggregated test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			ok, err := IsInSubGroupBatchAggregated(sample(mixer), batchOptions)
+			return err == nil && ok
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchAggregated test should not pass", prop.ForAll(
+		func(mixer fr.Element, a fp.Element) bool {
+			result := sample(mixer)
+			// random points in the h-torsion
+			h := fuzzCofactorOfG1(a)
+			result[0].FromJacobian(&h)
+
+			ok, err := IsInSubGroupBatchAggregated(result, batchOptions)
+			return err == nil && !ok
+		},
+		GenFr(),
+		GenFp(),
+	))
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchAggregated test should not pass for points that pass the Tate pairings test", prop.ForAll(
+		func(mixer fr.Element, a fp.Element) bool {
+			result := sample(mixer)
+			h := fuzzTateOneNotInG1(a)
+			result[nbSamples/2].FromJacobian(&h)
+
+			ok, err := IsInSubGroupBatchAggregated(result, batchOptions)
+			return err == nil && !ok
+		},
+		GenFr(),
+		GenFp(),
+	))
+
+	properties.Property("[BLS12-381] isTateOneAggregated and checkPoint should agree", prop.ForAll(
+		func(mixer fr.Element, a fp.Element) bool {
+			result := sample(mixer)
+			h := fuzzCofactorOfG1(a)
+			result[nbSamples-1].FromJacobian(&h)
+
+			expected := checkPoint(&result[nbSamples-1]) == nil
+			rounds3, rounds11, _ := batchOptions.tateRounds()
+			return isTateOneAggregated(result, rounds3, rounds11, rand.Reader) == expected
+		},
+		GenFr(),
+		GenFp(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestIsInSubGroupBatchAggregatedOptions(t *testing.T) {
+	t.Parallel()
+
+	_, _, g, _ := curve.Generators()
+	points := []curve.G1Affine{g, {}, g}
+	for _, opts := range []BatchOptions{
+		{SecurityLevel: 1},
+		{SecurityLevel: MaxSecurityLevel},
+		{DomainTag: []byte("test")},
+	} {
+		if ok, err := IsInSubGroupBatchAggregated(points, opts); err != nil || !ok {
+			t.Fatalf("%+v: expected points in G1, got %v, %v", opts, ok, err)
+		}
+	}
+	if ok, err := IsInSubGroupBatchAggregated(nil, batchOptions); err != nil || !ok {
+		t.Fatalf("expected no point in G1, got %v, %v", ok, err)
+	}
+
+	// β=64 gives 41 rounds of order 3, 3⁴¹ ≥ 2⁶⁴ > 3⁴⁰, and 64 rounds of order 11
+	if rounds3, rounds11, err := batchOptions.tateRounds(); err != nil || rounds3 != 41 || rounds11 != 64 {
+		t.Fatalf("unexpected rounds %d, %d, %v", rounds3, rounds11, err)
+	}
+
+	// (0,2) has order 3 and y-2 = 0 is rejected directly
+	var torsion3 curve.G1Affine
+	torsion3.Y.SetUint64(2)
+	if !torsion3.IsOnCurve() {
+		t.Fatal("(0,2) should be on the curve")
+	}
+	if ok, err := IsInSubGroupBatchAggregated([]curve.G1Affine{g, torsion3}, batchOptions); err != nil || ok {
+		t.Fatalf("expected a point of order 3, got %v, %v", ok, err)
+	}
+
+	var offCurve curve.G1Affine
+	offCurve.X.SetOne()
+	offCurve.Y.SetOne()
+	if ok, err := IsInSubGroupBatchAggregated([]curve.G1Affine{g, offCurve}, batchOptions); err != nil || ok {
+		t.Fatalf("expected a point not on the curve, got %v, %v", ok, err)
+	}
+
+	if _, err := IsInSubGroupBatchAggregated(points, BatchOptions{SecurityLevel: -1}); !errors.Is(err, ErrInvalidSecurityLevel) {
+		t.Fatalf("expected ErrInvalidSecurityLevel, got %v", err)
+	}
+	if _, err := IsInSubGroupBatchAggregated(points, BatchOptions{Rand: failingReader{}}); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+}
+
+func BenchmarkIsInSubGroupBatchAggregated(b *testing.B) {
+	const (
+		pow       = 14
+		nbSamples = 1 << pow
+	)
+	var sampleScalars [nbSamples]fr.Element
+	fillBenchScalars(sampleScalars[:])
+
+	_, _, g, _ := curve.Generators()
+	result := curve.BatchScalarMultiplicationG1(&g, sampleScalars[:])
+	rounds3, rounds11, _ := batchOptions.tateRounds()
+
+	for i := 8; i <= pow; i += 3 {
+		using := 1 << i
+		b.Run(fmt.Sprintf("%d points-tate per-point", using), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				for k := range result[:using] {
+					checkPoint(&result[k])
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("%d points-tate aggregated", using), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				isTateOneAggregated(result[:using], rounds3, rounds11, rand.Reader)
+			}
+		})
+		b.Run(fmt.Sprintf("%d points-per-point", using), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatch(result[:using], batchOptions)
+			}
+		})
+		b.Run(fmt.Sprintf("%d points-aggregated", using), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchAggregated(result[:using], batchOptions)
+			}
+		})
+	}
+}
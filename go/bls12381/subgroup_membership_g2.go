@@ -0,0 +1,145 @@
+package bls12381
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/yelhousni/batch-subgroup-membership/go/parallel"
+)
+
+// IsInSubGroupBatchNaiveG2 checks if a batch of points Q_i are in G2.
+// This is a naive method that checks each point individually using Scott test
+// [Scott21].
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func IsInSubGroupBatchNaiveG2(points []curve.G2Affine) bool {
+	for i := range points {
+		if !points[i].IsInSubGroup() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsInSubGroupBatchG2 checks if a batch of points Q_i are in G2.
+// First, it checks that all points are on the curve. No Tate pairings test is
+// cheap enough on the twist, see boundBitsG2.
+// Second, it generates random scalars s_i in the range [0, 2^3), performs
+// n=⌈β/3⌉ multi-scalar-multiplication Sj=∑[s_i]Q_i of sizes N=len(points) and
+// checks if Sj are in G2 using Scott test [Scott21], where β is the security
+// level of opts.
+//
+// It returns an error if opts is invalid or if opts.Rand fails.
+//
+// [Scott21]: https://eprint.iacr.org/2021/1130.pdf
+func IsInSubGroupBatchG2(points []curve.G2Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchG2Context(context.Background(), points, opts)
+}
+
+// IsInSubGroupBatchG2Context is like IsInSubGroupBatchG2 but gives up when ctx
+// is done: the on-curve loop and the multi-scalar-multiplication goroutines
+// stop promptly and ctx.Err() is returned.
+func IsInSubGroupBatchG2Context(ctx context.Context, points []curve.G2Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBitsG2()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	for i := range points {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if !points[i].IsOnCurve() {
+			return false, nil
+		}
+	}
+
+	// 2. Check Sj are in G2
+	opts = opts.bindPointsG2(points)
+	return msmCheckRoundsG2(ctx, points, roundsBits, &opts)
+}
+
+func IsInSubGroupBatchG2Parallel(points []curve.G2Affine, opts BatchOptions) (bool, error) {
+	return IsInSubGroupBatchG2ParallelContext(context.Background(), points, opts)
+}
+
+// IsInSubGroupBatchG2ParallelContext is like IsInSubGroupBatchG2Parallel but
+// gives up when ctx is done, see IsInSubGroupBatchG2Context.
+func IsInSubGroupBatchG2ParallelContext(ctx context.Context, points []curve.G2Affine, opts BatchOptions) (bool, error) {
+	roundsBits, err := opts.roundsBitsG2()
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Check points are on the curve
+	var nbErrors int64
+	err = parallel.ExecuteContext(ctx, len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			if !points[i].IsOnCurve() {
+				atomic.AddInt64(&nbErrors, 1)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+	if nbErrors > 0 {
+		return false, nil
+	}
+
+	// 2. Check Sj are in G2
+	opts = opts.bindPointsG2(points)
+	return msmCheckRoundsG2Parallel(ctx, points, roundsBits, &opts)
+}
+
+// msmCheckRoundsG2 checks that the random linear combinations Sj=∑[s_i]Q_i are
+// in G2, where the scalars of Sj are drawn in [0, 2^roundsBits[j]) from
+// opts.Rand.
+func msmCheckRoundsG2(ctx context.Context, points []curve.G2Affine, roundsBits []int, opts *BatchOptions) (bool, error) {
+	for _, nbBits := range roundsBits {
+		sources, err := opts.randomSources(msmNbChunks(nbBits))
+		if err != nil {
+			return false, err
+		}
+		ok, err := _msmCheckG2(ctx, points, nbBits, sources)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// msmCheckRoundsG2Parallel is like msmCheckRoundsG2 but checks the random
+// linear combinations in parallel.
+func msmCheckRoundsG2Parallel(ctx context.Context, points []curve.G2Affine, roundsBits []int, opts *BatchOptions) (bool, error) {
+	// opts.Rand is only read from the calling goroutine, so the random sources
+	// of all the rounds are drawn beforehand.
+	sources := make([][]io.Reader, len(roundsBits))
+	for i, nbBits := range roundsBits {
+		var err error
+		if sources[i], err = opts.randomSources(msmNbChunks(nbBits)); err != nil {
+			return false, err
+		}
+	}
+	var nbErrors int64
+	err := parallel.ExecuteContext(ctx, len(roundsBits), func(start, end int) {
+		for i := start; i < end; i++ {
+			if ok, _ := _msmCheckG2(ctx, points, roundsBits[i], sources[i]); !ok {
+				atomic.AddInt64(&nbErrors, 1)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return nbErrors == 0, nil
+}
@@ -0,0 +1,274 @@
+package bls12381
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestIsInSubGroupBatchG2(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = 20
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	// number of points to test
+	const nbSamples = 100
+
+	// random points in G2
+	sample := func(mixer fr.Element) []curve.G2Affine {
+		// mixer ensures that all the words of a frElement are set
+		var sampleScalars [nbSamples]fr.Element
+		for i := 1; i <= nbSamples; i++ {
+			sampleScalars[i-1].SetUint64(uint64(i)).
+				Mul(&sampleScalars[i-1], &mixer)
+		}
+		_, _, _, g := curve.Generators()
+		return curve.BatchScalarMultiplicationG2(&g, sampleScalars[:])
+	}
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchG2 test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			result := sample(mixer)
+			ok, err := IsInSubGroupBatchG2(result, batchOptions)
+			return err == nil && ok && IsInSubGroupBatchNaiveG2(result)
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchG2Parallel test should pass", prop.ForAll(
+		func(mixer fr.Element) bool {
+			result := sample(mixer)
+			ok, err := IsInSubGroupBatchG2Parallel(result, batchOptions)
+			return err == nil && ok
+		},
+		GenFr(),
+	))
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchG2 test should not pass", prop.ForAll(
+		func(mixer fr.Element, a curve.E2) bool {
+			result := sample(mixer)
+			// a random point of E'(𝔽p²), not in G2
+			p := curve.GeneratePointNotInG2(a)
+			result[nbSamples/2].FromJacobian(&p)
+
+			ok, err := IsInSubGroupBatchG2(result, batchOptions)
+			okParallel, errParallel := IsInSubGroupBatchG2Parallel(result, batchOptions)
+			return err == nil && !ok && errParallel == nil && !okParallel && !IsInSubGroupBatchNaiveG2(result)
+		},
+		GenFr(),
+		GenE2(),
+	))
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchG2 test should not pass for a component of order 13", prop.ForAll(
+		func(mixer fr.Element, a curve.E2) bool {
+			result := sample(mixer)
+			// Q+T where T has order 13, the smallest prime factor of h2
+			t := fuzzTorsion13OfG2(a)
+			if t.Z.IsZero() {
+				return true
+			}
+			if t13 := mulNotInG2(&t, big.NewInt(13)); !t13.Z.IsZero() {
+				return false
+			}
+			var q curve.G2Jac
+			q.FromAffine(&result[nbSamples/2])
+			q.AddAssign(&t)
+			result[nbSamples/2].FromJacobian(&q)
+
+			ok, err := IsInSubGroupBatchG2(result, batchOptions)
+			okParallel, errParallel := IsInSubGroupBatchG2Parallel(result, batchOptions)
+			return err == nil && !ok && errParallel == nil && !okParallel && !IsInSubGroupBatchNaiveG2(result)
+		},
+		GenFr(),
+		GenE2(),
+	))
+
+	properties.Property("[BLS12-381] IsInSubGroupBatchG2 test should not pass for points not on the curve", prop.ForAll(
+		func(mixer fr.Element, a curve.E2) bool {
+			result := sample(mixer)
+			result[0].Y.Mul(&result[0].Y, &a)
+			if result[0].IsOnCurve() {
+				return true
+			}
+
+			ok, err := IsInSubGroupBatchG2(result, batchOptions)
+			okParallel, errParallel := IsInSubGroupBatchG2Parallel(result, batchOptions)
+			return err == nil && !ok && errParallel == nil && !okParallel
+		},
+		GenFr(),
+		GenE2(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestIsInSubGroupBatchG2Options(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, g := curve.Generators()
+	points := []curve.G2Affine{g, {}, g}
+	for _, opts := range []BatchOptions{
+		{SecurityLevel: 1},
+		{SecurityLevel: MaxSecurityLevel},
+		{DomainTag: []byte("test")},
+	} {
+		if ok, err := IsInSubGroupBatchG2(points, opts); err != nil || !ok {
+			t.Fatalf("%+v: expected points in G2, got %v, %v", opts, ok, err)
+		}
+	}
+	if ok, err := IsInSubGroupBatchG2(nil, batchOptions); err != nil || !ok {
+		t.Fatalf("expected no point in G2, got %v, %v", ok, err)
+	}
+
+	// β=64 gives 21 rounds of 3 bits and a last round of 1 bit
+	roundsBits, err := batchOptions.roundsBitsG2()
+	if err != nil || len(roundsBits) != 22 || roundsBits[0] != boundBitsG2 || roundsBits[21] != 1 {
+		t.Fatalf("unexpected rounds %v, %v", roundsBits, err)
+	}
+
+	// the transcripts of G1 and G2 are separated
+	var s1, s2 [32]byte
+	opts := BatchOptions{DomainTag: []byte("test")}
+	opts.bindPoints(nil).Rand.Read(s1[:])
+	opts.bindPointsG2(nil).Rand.Read(s2[:])
+	if s1 == s2 {
+		t.Fatal("G1 and G2 should have different transcripts")
+	}
+
+	for _, securityLevel := range []int{-1, MaxSecurityLevel + 1} {
+		opts := BatchOptions{SecurityLevel: securityLevel}
+		if _, err := IsInSubGroupBatchG2(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+		if _, err := IsInSubGroupBatchG2Parallel(points, opts); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+	}
+	if _, err := IsInSubGroupBatchG2(points, BatchOptions{Rand: failingReader{}}); !errors.Is(err, errFailingReader) {
+		t.Fatalf("expected errFailingReader, got %v", err)
+	}
+}
+
+func TestIsInSubGroupBatchG2Context(t *testing.T) {
+	t.Parallel()
+
+	_, _, _, g := curve.Generators()
+	points := make([]curve.G2Affine, 1<<12)
+	for i := range points {
+		points[i] = g
+	}
+	checks := []func(context.Context, []curve.G2Affine, BatchOptions) (bool, error){
+		IsInSubGroupBatchG2Context,
+		IsInSubGroupBatchG2ParallelContext,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, check := range checks {
+		if ok, err := check(ctx, points, batchOptions); err != nil || !ok {
+			t.Fatalf("expected points in G2, got %v, %v", ok, err)
+		}
+	}
+
+	// a cancelled context is reported
+	cancel()
+	for _, check := range checks {
+		if _, err := check(ctx, points, batchOptions); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+
+	// the multi-scalar-multiplication goroutines stop too
+	sources, err := batchOptions.randomSources(msmNbChunks(boundBitsG2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := _msmCheckG2(ctx, points, boundBitsG2, sources); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// a deadline in the middle of the checks is honoured
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := IsInSubGroupBatchG2Context(ctx, points, batchOptions); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func BenchmarkIsInSubGroupBatchG2(b *testing.B) {
+	const (
+		pow       = 14
+		nbSamples = 1 << pow
+	)
+	var sampleScalars [nbSamples]fr.Element
+	fillBenchScalars(sampleScalars[:])
+
+	_, _, _, g := curve.Generators()
+	result := curve.BatchScalarMultiplicationG2(&g, sampleScalars[:])
+
+	for i := 5; i <= pow; i += 3 {
+		using := 1 << i
+		b.Run(fmt.Sprintf("%d points-naive", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchNaiveG2(result[:using])
+			}
+		})
+		b.Run(fmt.Sprintf("%d points", using), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				IsInSubGroupBatchG2(result[:using], batchOptions)
+			}
+		})
+	}
+}
+
+// fuzzTorsion13OfG2 returns [r·h2/13]Q for a random point Q of E'(𝔽p²), which
+// is a point of order 13 or the point at infinity.
+func fuzzTorsion13OfG2(f curve.E2) curve.G2Jac {
+	// h2 = (x⁸-4x⁷+5x⁶-4x⁴+6x³-4x²-4x+13)/9
+	var x, h2, t big.Int
+	x.SetString("-15132376222941642752", 10) // -0xd201000000010000
+	for i, c := range []int64{1, -4, 5, 0, -4, 6, -4, -4, 13} {
+		if i > 0 {
+			h2.Mul(&h2, &x)
+		}
+		h2.Add(&h2, t.SetInt64(c))
+	}
+	h2.Div(&h2, t.SetInt64(9))
+
+	var s big.Int
+	s.Mul(&h2, fr.Modulus()).Div(&s, t.SetInt64(13))
+
+	q := curve.GeneratePointNotInG2(f)
+	return mulNotInG2(&q, &s)
+}
+
+// mulNotInG2 returns [s]q using a double-and-add, since G2Jac.ScalarMultiplication
+// uses an endomorphism that is only valid in G2.
+func mulNotInG2(q *curve.G2Jac, s *big.Int) curve.G2Jac {
+	var res curve.G2Jac
+	res.X.SetOne()
+	res.Y.SetOne()
+	for i := s.BitLen() - 1; i >= 0; i-- {
+		res.DoubleAssign()
+		if s.Bit(i) == 1 {
+			res.AddAssign(q)
+		}
+	}
+	return res
+}
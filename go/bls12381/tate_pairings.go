@@ -1,10 +1,6 @@
 package bls12381
 
 import (
-	"math"
-	"math/big"
-	"unsafe"
-
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
 	"github.com/yelhousni/batch-subgroup-membership/go/eisenstein"
@@ -30,11 +26,18 @@ func isSecondTateOne(point curve.G1Affine) bool {
 }
 
 func tateP11(point curve.G1Affine, lines [7]line) *fp.Element {
+	num, denom := tateP11Fraction(&point, &lines)
+	return tateP11Reduce(&num, &denom)
+}
+
+// tateP11Fraction returns the numerator and the denominator of the Miller
+// function f_{11,P}(Q), before the final exponentiation.
+func tateP11Fraction(point *curve.G1Affine, lines *[7]line) (num, denom fp.Element) {
 
 	// f_{11,P} = (l_{P,P}^4 * (l_{4P,P} * l_{2P,2P})^2 * l_{5P,5P}) /
 	// 			  (v_{2P}^4 * (v_{5P} * v_{4P})^2)
 
-	var num, denom, tate, f1, f2 fp.Element
+	var f1, f2 fp.Element
 
 	// l_{P,P}^4
 	f1.Mul(&point.X, &lines[0].a).Add(&f1, &point.Y).Add(&f1, &lines[0].b)
@@ -57,13 +60,20 @@ func tateP11(point curve.G1Affine, lines [7]line) *fp.Element {
 	f1.Mul(&f1, &f2).Square(&f1)
 	denom.Mul(&denom, &f1)
 
+	return num, denom
+}
+
+// tateP11Reduce returns (num/denom)^((p-1)/11).
+func tateP11Reduce(num, denom *fp.Element) *fp.Element {
+	var tate, f1, f2 fp.Element
+
 	// denom^{-1} = denom^{10} inside the 11-th power residue symbol
-	f1.Square(&denom)
+	f1.Square(denom)
 	f2.Square(&f1).Square(&f2)
-	denom.Mul(&f1, &f2)
+	f2.Mul(&f1, &f2)
 
 	// tate = num * denom^{-1}
-	tate.Mul(&num, &denom)
+	tate.Mul(num, &f2)
 
 	// tate^((p-1)/11)
 	tate = expByp11(tate)
@@ -93,23 +103,10 @@ type line struct {
 
 var lines1, lines2 [7]line
 
-var a, b big.Int
 var beta eisenstein.ComplexNumber
 var two_p fp.Element
-var one, mone, three big.Int
-var wordPow2Mod9 uint64
-var betaTermMMod9 uint64
-var betaTermNMod9 uint64
 
 func init() {
-	// Cache 2^W mod 9 for mod9()
-	switch unsafe.Sizeof(big.Word(0)) {
-	case 4: // uint32
-		wordPow2Mod9 = 4
-	case 8: // uint64
-		wordPow2Mod9 = 7
-	}
-
 	// P = (
 	// 	   0x1147c19050b3c4b663a4ca29c4859eeb1ac05a91659009602e7443347ad659e9f838f4ed07337c4c6d3a48d612b4bb92,
 	// 	   0x8d7c25237c7dcea6ea0c6c37053882c59cc0ee424b3545bb25116d53e383574063149edb438b959dd169d0e01b2d3bc,
@@ -157,25 +154,11 @@ func init() {
 	lines2[6].b.SetString("2936782925110917657004772320034083346502209258972949212348266083811354308950004356358196405296552596411593186618131")
 
 	// beta = a+ω*b with b primary and norm(beta)=p
-	a.SetString("-1155048275357884106335086113613464118783412807316232579754", 10)
-	b.SetString("1155048275357884106335086113613464118768280431093290937003", 10)
-	beta.A0 = a
-	beta.A1 = b
+	beta.A0.SetString("-1155048275357884106335086113613464118783412807316232579754", 10)
+	beta.A1.SetString("1155048275357884106335086113613464118768280431093290937003", 10)
 
 	// some constatns
 	two_p.SetUint64(2)
-	one.SetUint64(1)
-	mone.Neg(&one)
-	three.SetUint64(3)
-
-	// Precompute mod-9 terms for the cubic symbol exponent.
-	var betaA0Sq, betaA0A1 big.Int
-	betaA0Sq.Mul(&beta.A0, &beta.A0)
-	betaA0A1.Mul(&beta.A0, &beta.A1)
-	betaA0SqMod9 := mod9(&betaA0Sq)
-	betaA0A1Mod9 := mod9(&betaA0A1)
-	betaTermMMod9 = mod9Int(1 - int64(betaA0SqMod9))
-	betaTermNMod9 = mod9Int(int64(betaA0SqMod9) - int64(betaA0A1Mod9) - 1)
 }
 
 // expByp11 uses a short addition chain to compute x^p11 where p11=(p-1)/11 .
@@ -670,245 +653,30 @@ func IsCubicResidue(x *fp.Element) bool {
 	return IsCubicResidueFast(x)
 }
 
+// CubicSymbol returns the cubic residue symbol [x/β]₃, i.e. 0, 1, ω or ω².
 func CubicSymbol(x fp.Element) *eisenstein.ComplexNumber {
 	// α = x + ω * 0
 	var alpha eisenstein.ComplexNumber
 	x.BigInt(&alpha.A0)
 	alpha.A1.SetUint64(0)
-	// norm(β) = p, with β[1] = 0 mod 3 and β[0] ≠ 0
-	var _beta eisenstein.ComplexNumber
-	_beta.Set(&beta)
-	return cubicSymbol(&alpha, &_beta)
-}
-
-func cubicSymbol(alpha, beta *eisenstein.ComplexNumber) *eisenstein.ComplexNumber {
-	var result, gamma eisenstein.ComplexNumber
-	result.SetOne()
-
-	for {
-		// Base cases: if α = ±1 or β = ±1 return result
-		if (alpha.A1.Sign() == 0 && (alpha.A0.Cmp(&one) == 0 || alpha.A0.Cmp(&mone) == 0)) ||
-			(beta.A1.Sign() == 0 && (beta.A0.Cmp(&one) == 0 || beta.A0.Cmp(&mone) == 0)) {
-			return &result
-		}
-
-		// q = ⌊α/β⌉
-		// γ = α - q * β
-		gamma.Quo(alpha, beta)
-		gamma.Mul(&gamma, beta)
-		gamma.Sub(alpha, &gamma)
-
-		// If γ = 0 return 0
-		if gamma.A0.Sign() == 0 && gamma.A1.Sign() == 0 {
-			return &eisenstein.ComplexNumber{}
-		}
-
-		// Remove ramified factors:
-		// Compute m≥0 s.t. γ' = γ / (1-ω)^m is not divisible by 1-ω
-		// i.e. γ'[0]+γ'[1] ≠ 0 mod 3.
-		//
-		// We use:
-		// 		(γ / (1-ω))[0] = (2γ[0] - γ[1]) / 3
-		// 		(γ / (1-ω))[1] = (γ[0] + γ[1]) / 3
-		m := uint64(0)
-		r1 := mod3AbsSum(&gamma.A0, &gamma.A1)
-
-		// the result is integral iff γ[0] + γ[1] = 0 mod 3
-		for r1 == 0 {
-			alpha.A1.Add(&gamma.A0, &gamma.A1)
-			alpha.A0.Add(&gamma.A0, &gamma.A0).
-				Sub(&alpha.A0, &gamma.A1)
-			gamma.A0.Quo(&alpha.A0, &three)
-			gamma.A1.Quo(&alpha.A1, &three)
-
-			m++
-
-			r1 = mod3AbsSum(&gamma.A0, &gamma.A1)
-		}
-
-		// Make primary:
-		// Find n with 0 ≤ n < 3 so that γ'/ω^n is primary.
-		//
-		// Division by ω is a multiplication by ω^2 so the result is one of:
-		// 		γ[0] + ω * γ[1]
-		// 		γ[1] - γ[0] - ω * γ[0]
-		// 		-γ[1] - ω * (γ[0] - γ[1])
-		n := 0
-		r0 := mod3(&gamma.A0)
-		r1 = mod3AbsDiff(&gamma.A0, &gamma.A1)
-		if r0 == 0 {
-			n = 1
-			alpha.A1.Sub(&gamma.A0, &gamma.A1)
-			gamma.A1.Neg(&gamma.A0)
-			gamma.A0.Neg(&alpha.A1)
-		} else if r1 == 0 {
-			n = 2
-			alpha.A1.Sub(&gamma.A0, &gamma.A1)
-			gamma.A0.Neg(&gamma.A1)
-			gamma.A1.Set(&alpha.A1)
-		}
-
-		// Compute ω^exp, where
-		// 		exp = ( n * (β[0]^2 − β[0]*β[1] − 1) + m * (1 - β[0]^2) ) / 3
-		if n < 0 || n > 2 {
-			panic("unexpected value for n in cubic symbol computation")
-		}
-		mMod9 := m % 9
-		q0mod9 := (mMod9*betaTermMMod9 + uint64(n)*betaTermNMod9) % 9
-
-		// Multiply the result by one of 1, ω, ω^2.
-		//
-		// We avoid dividing exp by 3 and switch on the value of exp mod 9.
-		r0 = q0mod9
-		switch r0 {
-		case 0:
-			// muliply the result by 1
-		case 3:
-			// muliply the result by ω
-			alpha.A0.Neg(&result.A1)
-			result.A1.Add(&result.A0, &alpha.A0)
-			result.A0.Set(&alpha.A0)
-		case 6:
-			// muliply the result by ω^2
-			alpha.A1.Neg(&result.A0)
-			result.A0.Add(&result.A1, &alpha.A1)
-			result.A1.Set(&alpha.A1)
-		default:
-			panic("unexpected value in cubic symbol computation")
-		}
-
-		// Swap for the next iteration
-		alpha.Set(beta)
-		beta.Set(&gamma)
-	}
-}
-
-func mod3(z *big.Int) uint64 {
-	limbs := z.Bits()
-	var sumOfLimbsMod3 uint64
-	for _, limb := range limbs {
-		sumOfLimbsMod3 = (sumOfLimbsMod3 + (uint64(limb) % 3)) % 3
-	}
-	return sumOfLimbsMod3
-}
-
-func cmpAbs(x, y *big.Int) int {
-	xb := x.Bits()
-	yb := y.Bits()
-	if len(xb) != len(yb) {
-		if len(xb) > len(yb) {
-			return 1
-		}
-		return -1
-	}
-	if len(xb) == 0 {
-		return 0
-	}
-	for i := len(xb) - 1; i >= 0; i-- {
-		if xb[i] == yb[i] {
-			continue
-		}
-		if xb[i] > yb[i] {
-			return 1
-		}
-		return -1
-	}
-	return 0
-}
-
-func mod3AbsSum(x, y *big.Int) uint64 {
-	if x.Sign() == 0 {
-		return mod3(y)
-	}
-	if y.Sign() == 0 {
-		return mod3(x)
-	}
-	mx := mod3(x)
-	my := mod3(y)
-	if x.Sign() == y.Sign() {
-		return (mx + my) % 3
-	}
-	if cmpAbs(x, y) >= 0 {
-		return (mx + 3 - my) % 3
-	}
-	return (my + 3 - mx) % 3
-}
-
-func mod3AbsDiff(x, y *big.Int) uint64 {
-	if x.Sign() == 0 {
-		return mod3(y)
-	}
-	if y.Sign() == 0 {
-		return mod3(x)
-	}
-	mx := mod3(x)
-	my := mod3(y)
-	if x.Sign() != y.Sign() {
-		return (mx + my) % 3
-	}
-	if cmpAbs(x, y) >= 0 {
-		return (mx + 3 - my) % 3
-	}
-	return (my + 3 - mx) % 3
-}
-
-func mod9Int(v int64) uint64 {
-	v %= 9
-	if v < 0 {
-		v += 9
-	}
-	return uint64(v)
-}
-
-func mod9(z *big.Int) uint64 {
-	limbs := z.Bits()
-	var sumOfLimbsMod9 uint64
 
-	// Determine the effective power of 2 for each limb based on Word size.
-	// wordPow2Mod9 is cached at init; fallback if it's unset.
-	if wordPow2Mod9 == 0 {
-		switch unsafe.Sizeof(big.Word(0)) {
-		case 4: // uint32
-			wordPow2Mod9 = 4
-		case 8: // uint64
-			wordPow2Mod9 = 7
-		default:
-			return z.Mod(z, big.NewInt(9)).Uint64()
-		}
-	}
-
-	currentMultiplier := uint64(1) // Represents (2^W)^i % 9 for the current limb
-
-	for _, limb := range limbs {
-		// Each limb represents limb_val * (2^W)^i
-		// The overall sum is sum (limb_val_i * (2^W)^i)
-		// We need to sum (limb_val_i % 9 * ((2^W)^i % 9)) % 9
-
-		limbValMod9 := uint64(limb) % 9
-		term := (limbValMod9 * currentMultiplier) % 9
-		sumOfLimbsMod9 = (sumOfLimbsMod9 + term) % 9
-
-		// Update multiplier for the next limb: next_multiplier = current_multiplier * (2^W % 9)
-		currentMultiplier = (currentMultiplier * wordPow2Mod9) % 9
-	}
-
-	// Handle negative numbers: math/big's Rem returns a result with the same sign as z.
-	// For modulo, we typically want a non-negative result.
-	if z.Sign() < 0 && sumOfLimbsMod9 != 0 {
-		return 9 - sumOfLimbsMod9
-	}
-	return sumOfLimbsMod9
-}
-
-func increment(z *big.Int) {
-	if z.Sign() > 0 {
-		zBits := z.Bits()
-		if zBits[0] < math.MaxUint64 {
-			zBits[0] = big.Word(uint64(zBits[0]) + 1)
-			return
-		}
-	}
-	z.Add(z, &one)
+	var result eisenstein.ComplexNumber
+	k, err := eisenstein.CubicResidueSymbol(&alpha, &beta)
+	if err != nil {
+		// x = 0
+		return &result
+	}
+	// result = ω^k
+	switch k {
+	case 0:
+		result.SetOne()
+	case 1:
+		result.A1.SetInt64(1)
+	case 2:
+		result.A0.SetInt64(-1)
+		result.A1.SetInt64(-1)
+	}
+	return &result
 }
 
 // expByp3 uses a short addition chain to compute x^p3 where p3=(p-1)/3 .
@@ -0,0 +1,177 @@
+package eisenstein
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+var (
+	// ErrDivisibleBy1MinusOmega means that an Eisenstein integer is divisible
+	// by the ramified prime 1-ω, e.g. it is zero.
+	ErrDivisibleBy1MinusOmega = errors.New("divisible by 1-ω")
+
+	// ErrNotCoprime means that α and β are not coprime, i.e. [α/β]₃ = 0.
+	ErrNotCoprime = errors.New("α and β are not coprime")
+)
+
+// IsPrimary returns true if z is primary, i.e. z ≡ 2 mod 3: z[0] ≡ 2 mod 3
+// and z[1] ≡ 0 mod 3.
+func (z *ComplexNumber) IsPrimary() bool {
+	return mod3(&z.A0) == 2 && mod3(&z.A1) == 0
+}
+
+// MakePrimary sets z to the primary associate of x, and returns k such that
+// x = ±ω^k·z.
+//
+// It returns ErrDivisibleBy1MinusOmega if 1-ω divides x, in which case x has
+// no primary associate and z is not modified.
+func (z *ComplexNumber) MakePrimary(x *ComplexNumber) (int, error) {
+	r0 := mod3(&x.A0)
+	r1 := mod3(&x.A1)
+	if (r0+r1)%3 == 0 {
+		return 0, ErrDivisibleBy1MinusOmega
+	}
+
+	// x ≡ ±1, ±ω² or ±ω mod 3, and z = ±ω^{-k}·x, with
+	//
+	//	ω·x = -x[1] + (x[0]-x[1])ω
+	//	ω²·x = (x[1]-x[0]) - x[0]ω
+	k := 0
+	switch {
+	case r1 == 0:
+		z.Set(x)
+	case r0 == 0:
+		k = 1
+		z.t0.Sub(&x.A1, &x.A0)
+		z.A1.Neg(&x.A0)
+		z.A0.Set(&z.t0)
+	default:
+		k = 2
+		z.t0.Sub(&x.A0, &x.A1)
+		z.A0.Neg(&x.A1)
+		z.A1.Set(&z.t0)
+	}
+	if mod3(&z.A0) == 1 {
+		z.Neg(z)
+	}
+	return k, nil
+}
+
+// Valuation1MinusOmega sets z to x/(1-ω)ᵐ, where m is the largest integer
+// such that (1-ω)ᵐ divides x, and returns m. It returns -1 if x is zero, in
+// which case z is set to zero.
+//
+// We use:
+//
+//	(x / (1-ω))[0] = (2x[0] - x[1]) / 3
+//	(x / (1-ω))[1] = (x[0] + x[1]) / 3
+//
+// which is integral iff x[0] + x[1] ≡ 0 mod 3.
+func (z *ComplexNumber) Valuation1MinusOmega(x *ComplexNumber) int {
+	z.Set(x)
+	if z.A0.Sign() == 0 && z.A1.Sign() == 0 {
+		return -1
+	}
+	m := 0
+	for (mod3(&z.A0)+mod3(&z.A1))%3 == 0 {
+		z.t0.Add(&z.A0, &z.A1)
+		z.t1.Lsh(&z.A0, 1).Sub(&z.t1, &z.A1)
+		z.A0.Quo(&z.t1, three)
+		z.A1.Quo(&z.t0, three)
+		m++
+	}
+	return m
+}
+
+// CubicResidueSymbol returns k in {0, 1, 2} such that the cubic residue symbol
+// [α/β]₃ is ωᵏ. For a prime β, [α/β]₃ ≡ α^((N(β)-1)/3) mod β; it is extended
+// multiplicatively to the other β, with [α/u]₃ = 1 for a unit u. The symbol
+// only depends on the ideal (β), so that β can be any associate.
+//
+// It returns ErrDivisibleBy1MinusOmega if 1-ω divides β, and ErrNotCoprime if
+// α and β are not coprime, i.e. if [α/β]₃ = 0.
+func CubicResidueSymbol(alpha, beta *ComplexNumber) (int, error) {
+	var a, b, gamma ComplexNumber
+	a.Set(alpha)
+	if _, err := b.MakePrimary(beta); err != nil {
+		return 0, err
+	}
+
+	k := uint64(0)
+	for {
+		// Base cases: if α = ±1 or β = ±1 return result
+		if isRealUnit(&a) || isRealUnit(&b) {
+			return int(k), nil
+		}
+
+		// q = ⌊α/β⌉
+		// γ = α - q * β
+		gamma.Quo(&a, &b)
+		gamma.Mul(&gamma, &b)
+		gamma.Sub(&a, &gamma)
+
+		// Remove ramified factors: γ = (1-ω)ᵐ·γ'
+		m := gamma.Valuation1MinusOmega(&gamma)
+		if m < 0 {
+			return 0, ErrNotCoprime
+		}
+
+		// Make primary: γ' = ±ωⁿ·γ''
+		n, _ := gamma.MakePrimary(&gamma)
+
+		// The supplementary laws for the primary β give
+		// [γ/β]₃ = ω^exp·[γ''/β]₃, where
+		// 		exp = ( n * (β[0]^2 − β[0]*β[1] − 1) + m * (1 - β[0]^2) ) / 3
+		//
+		// We avoid dividing exp by 3 and compute 3·exp mod 9.
+		b0 := mod9(&b.A0)
+		b1 := mod9(&b.A1)
+		termM := (1 + 9 - b0*b0%9) % 9
+		termN := (b0*b0%9 + 18 - b0*b1%9 - 1) % 9
+		k = (k + (uint64(m)%9*termM+uint64(n)*termN)%9/3) % 3
+
+		// Cubic reciprocity: [γ''/β]₃ = [β/γ'']₃ for γ'' and β primary
+		a.Set(&b)
+		b.Set(&gamma)
+	}
+}
+
+var three = big.NewInt(3)
+
+// isRealUnit returns true if z = ±1.
+func isRealUnit(z *ComplexNumber) bool {
+	return z.A1.Sign() == 0 && z.A0.IsInt64() && (z.A0.Int64() == 1 || z.A0.Int64() == -1)
+}
+
+// mod3 returns z mod 3 in [0,2]. Since 2^32 ≡ 2^64 ≡ 1 mod 3, it sums the
+// words mod 3.
+func mod3(z *big.Int) uint64 {
+	var v uint64
+	for _, w := range z.Bits() {
+		v += uint64(w) % 3
+	}
+	v %= 3
+	if z.Sign() < 0 && v != 0 {
+		v = 3 - v
+	}
+	return v
+}
+
+// mod9 returns z mod 9 in [0,8]. 2^32 ≡ 4 mod 9 and 2^64 ≡ 7 mod 9.
+func mod9(z *big.Int) uint64 {
+	wordMod9 := uint64(7)
+	if bits.UintSize == 32 {
+		wordMod9 = 4
+	}
+	var v uint64
+	c := uint64(1) // 2^(i·W) mod 9
+	for _, w := range z.Bits() {
+		v = (v + uint64(w)%9*c) % 9
+		c = c * wordMod9 % 9
+	}
+	if z.Sign() < 0 && v != 0 {
+		v = 9 - v
+	}
+	return v
+}
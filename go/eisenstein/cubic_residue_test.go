@@ -0,0 +1,221 @@
+package eisenstein
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestPrimary(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("MakePrimary should output a primary associate", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b, c ComplexNumber
+			k, err := b.MakePrimary(a)
+			if (mod3(&a.A0)+mod3(&a.A1))%3 == 0 {
+				return errors.Is(err, ErrDivisibleBy1MinusOmega)
+			}
+			if err != nil || !b.IsPrimary() || k < 0 || k > 2 {
+				return false
+			}
+			// a = ±ω^k·b
+			c.Set(&b)
+			for i := 0; i < k; i++ {
+				c.Mul(&c, omega())
+			}
+			if !c.Equal(a) {
+				c.Neg(&c)
+			}
+			return c.Equal(a)
+		},
+		genE,
+	))
+
+	properties.Property("Having the receiver as operand (MakePrimary) should output the same result", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			k1, err1 := b.MakePrimary(a)
+			k2, err2 := a.MakePrimary(a)
+			return k1 == k2 && errors.Is(err1, err2) && (err1 != nil || a.Equal(&b))
+		},
+		genE,
+	))
+
+	properties.Property("Valuation1MinusOmega should output the (1-ω)-valuation", prop.ForAll(
+		func(a *ComplexNumber, m uint8) bool {
+			var b, c ComplexNumber
+			if _, err := b.MakePrimary(a); err != nil {
+				return true
+			}
+			// c = a·(1-ω)^m
+			c.Set(a)
+			for i := uint8(0); i < m%16; i++ {
+				c.Mul(&c, oneMinusOmega())
+			}
+			got := c.Valuation1MinusOmega(&c)
+			return got == int(m%16) && c.Equal(a)
+		},
+		genE,
+		gopter.Gen(func(genParams *gopter.GenParameters) *gopter.GenResult {
+			return gopter.NewGenResult(uint8(genParams.Rng.Intn(256)), gopter.NoShrinker)
+		}),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	var zero, z ComplexNumber
+	if m := z.Valuation1MinusOmega(&zero); m != -1 || !z.Equal(&zero) {
+		t.Fatalf("expected -1 for the valuation of 0, got %d", m)
+	}
+	if _, err := z.MakePrimary(&zero); !errors.Is(err, ErrDivisibleBy1MinusOmega) {
+		t.Fatalf("expected ErrDivisibleBy1MinusOmega, got %v", err)
+	}
+}
+
+func TestCubicResidueSymbol(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genC := GenCubicSymbolContext()
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("CubicResidueSymbol should output same result as Exp by (p-1)/3", prop.ForAll(
+		func(c *CubicSymbolContext, a *ComplexNumber) bool {
+			k, err := CubicResidueSymbol(a, c.Prime())
+
+			// a ≡ a[0] + a[1]·ω mod β
+			var x, sym big.Int
+			x.Mul(&a.A1, &c.omega).Add(&x, &a.A0).Mod(&x, &c.norm)
+			if x.Sign() == 0 {
+				return errors.Is(err, ErrNotCoprime)
+			}
+			sym.Exp(&x, &c.exp, &c.norm)
+			expected := 2
+			switch {
+			case sym.Cmp(big.NewInt(1)) == 0:
+				expected = 0
+			case sym.Cmp(&c.omega) == 0:
+				expected = 1
+			}
+			return err == nil && k == expected
+		},
+		genC,
+		genE,
+	))
+
+	properties.Property("CubicResidueSymbol should output same result as CubicSymbolContext", prop.ForAll(
+		func(c *CubicSymbolContext, x *big.Int) bool {
+			var a ComplexNumber
+			a.A0.Set(x)
+			k, err := CubicResidueSymbol(&a, c.Prime())
+			sym := c.Symbol(x)
+			if errors.Is(err, ErrNotCoprime) {
+				return sym == 0
+			}
+			return err == nil && k == int(sym)
+		},
+		genC,
+		GenNumber(384),
+	))
+
+	properties.Property("CubicResidueSymbol should be the same for the associates of β", prop.ForAll(
+		func(c *CubicSymbolContext, a *ComplexNumber) bool {
+			var beta ComplexNumber
+			beta.Mul(c.Prime(), omega()).Neg(&beta)
+			k1, err1 := CubicResidueSymbol(a, c.Prime())
+			k2, err2 := CubicResidueSymbol(a, &beta)
+			return k1 == k2 && errors.Is(err1, err2)
+		},
+		genC,
+		genE,
+	))
+
+	properties.Property("CubicResidueSymbol should be multiplicative in β", prop.ForAll(
+		func(c1, c2 *CubicSymbolContext, a *ComplexNumber) bool {
+			var beta ComplexNumber
+			beta.Mul(c1.Prime(), c2.Prime())
+			k, err := CubicResidueSymbol(a, &beta)
+			k1, err1 := CubicResidueSymbol(a, c1.Prime())
+			k2, err2 := CubicResidueSymbol(a, c2.Prime())
+			if err1 != nil || err2 != nil {
+				return errors.Is(err, ErrNotCoprime)
+			}
+			return err == nil && k == (k1+k2)%3
+		},
+		genC,
+		genC,
+		genE,
+	))
+
+	properties.Property("CubicResidueSymbol should satisfy the cubic reciprocity", prop.ForAll(
+		func(c1, c2 *CubicSymbolContext) bool {
+			k1, err1 := CubicResidueSymbol(c1.Prime(), c2.Prime())
+			k2, err2 := CubicResidueSymbol(c2.Prime(), c1.Prime())
+			return k1 == k2 && errors.Is(err1, err2)
+		},
+		genC,
+		genC,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// units, and β divisible by 1-ω
+	var a, beta ComplexNumber
+	a.A0.SetInt64(5)
+	beta.SetOne()
+	if k, err := CubicResidueSymbol(&a, &beta); err != nil || k != 0 {
+		t.Fatalf("expected [5/1]₃ = 1, got %d, %v", k, err)
+	}
+	for _, beta := range []*ComplexNumber{new(ComplexNumber), oneMinusOmega()} {
+		if _, err := CubicResidueSymbol(&a, beta); !errors.Is(err, ErrDivisibleBy1MinusOmega) {
+			t.Fatalf("expected ErrDivisibleBy1MinusOmega, got %v", err)
+		}
+	}
+}
+
+func omega() *ComplexNumber {
+	var z ComplexNumber
+	z.A1.SetInt64(1)
+	return &z
+}
+
+func oneMinusOmega() *ComplexNumber {
+	var z ComplexNumber
+	z.A0.SetInt64(1)
+	z.A1.SetInt64(-1)
+	return &z
+}
+
+// bench
+func BenchmarkCubicResidueSymbol(b *testing.B) {
+	var alpha, beta ComplexNumber
+	beta.A0.SetString(curveBetas[0].a, 10)
+	beta.A1.SetString(curveBetas[0].b, 10)
+	alpha.A0.SetString("2929494998551518193999723405412053246602204569353345363675794262224468384146017685416659704346369932930853282892458", 10)
+
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		CubicResidueSymbol(&alpha, &beta)
+	}
+}
@@ -0,0 +1,443 @@
+package eisenstein
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+var (
+	// ErrInvalidPrime means that p, or the norm of β, is not a prime p ≡ 1
+	// mod 3.
+	ErrInvalidPrime = errors.New("not a prime p ≡ 1 mod 3")
+
+	// ErrPrimeTooLarge means that the norm of β is not smaller than 2³⁸⁴.
+	ErrPrimeTooLarge = errors.New("norm of β is not smaller than 2³⁸⁴")
+)
+
+// A CubicSymbolContext computes cubic residue symbols [x/β]₃ modulo a fixed
+// Eisenstein prime β = a + b·ω of norm p ≡ 1 mod 3, with ℤ[ω]/(β) ≅ 𝔽p.
+//
+// The symbol is computed with a two-phase approach:
+//   - Phase 1: one Euclidean step to reduce x from ~log₂(p) bits to ~log₂(p)/2
+//     bits, with fixed-width Barrett-style reciprocals of p when p > 2²⁵⁷
+//   - Phase 2: Eisenstein GCD loop using fixed-width signed256/signed128 arithmetic
+//
+// A CubicSymbolContext is safe for concurrent use.
+type CubicSymbolContext struct {
+	betaA, betaB big.Int // β = a + b·ω, primary: a ≡ 2, b ≡ 0 mod 3
+	norm         big.Int // N(β) = a² + b² - ab = p
+	omega        big.Int // ω mod β, i.e. -a/b mod p
+	exp          big.Int // (p-1)/3, for the fallback
+
+	beta256A0, beta256A1 signed256 // a and b as signed256
+
+	// Precomputed constants for the fixed-width first reduction modulo β: the
+	// quotient q = round(x·conj(β)/p) is approximated by
+	// |q_re| = round(x·muRe/2³⁸⁴) and |q_im| = round(x·muIm/2³⁸⁴), with
+	// muRe = ⌊|a-b|·2³⁸⁴/p⌋ and muIm = ⌊|b|·2³⁸⁴/p⌋. Since x < 2³⁸⁴ the
+	// approximation is off by at most 1, and any quotient gives a valid
+	// remainder.
+	fixedWidth       bool // muRe and muIm fit in 256 bits
+	muRe, muIm       [4]uint64
+	muReNeg, muImNeg bool // signs of q_re and q_im, i.e. of a-b and -b
+}
+
+// NewCubicSymbolContext returns a context for the cubic residue symbol modulo
+// β = betaA + betaB·ω. β can be any associate: it is made primary.
+//
+// It returns ErrInvalidPrime if N(β) is not a prime p ≡ 1 mod 3, and
+// ErrPrimeTooLarge if p ≥ 2³⁸⁴.
+func NewCubicSymbolContext(betaA, betaB *big.Int) (*CubicSymbolContext, error) {
+	c := new(CubicSymbolContext)
+	c.betaA.Set(betaA)
+	c.betaB.Set(betaB)
+
+	var t1, t2, t3 big.Int
+	t1.Mul(&c.betaA, &c.betaA)
+	t2.Mul(&c.betaB, &c.betaB)
+	t3.Mul(&c.betaA, &c.betaB)
+	c.norm.Add(&t1, &t2).Sub(&c.norm, &t3)
+
+	if c.norm.BitLen() > 384 {
+		return nil, ErrPrimeTooLarge
+	}
+	// p = 3 is ramified, and the symbol is not defined modulo (1-ω)
+	if c.norm.Cmp(big.NewInt(3)) == 0 || !c.norm.ProbablyPrime(20) {
+		return nil, ErrInvalidPrime
+	}
+
+	// the supplementary laws of cubicCorrection are those of a primary β
+	var beta ComplexNumber
+	beta.A0.Set(&c.betaA)
+	beta.A1.Set(&c.betaB)
+	if _, err := beta.MakePrimary(&beta); err != nil {
+		return nil, ErrInvalidPrime
+	}
+	c.betaA.Set(&beta.A0)
+	c.betaB.Set(&beta.A1)
+
+	// β = a + b·ω ≡ 0 mod β, and 𝔽p ≅ ℤ[ω]/(β)
+	c.omega.ModInverse(&c.betaB, &c.norm)
+	c.omega.Mul(&c.omega, &c.betaA).Neg(&c.omega).Mod(&c.omega, &c.norm)
+	c.exp.Sub(&c.norm, big.NewInt(1)).Quo(&c.exp, big.NewInt(3))
+
+	bigToS256(&c.beta256A0, &c.betaA)
+	bigToS256(&c.beta256A1, &c.betaB)
+
+	// μ = ⌊|c|·2³⁸⁴/p⌋ for c = a-b and c = -b. Since 4p = (2b+(a-b))² + 3(a-b)²
+	// = (2(a-b)+b)² + 3b², |c| < √(4p/3) and μ < 2²⁵⁶ for p > 2²⁵⁷; for
+	// smaller p phase 1 is done with big.Int
+	var muRe, muIm big.Int
+	t1.Sub(&c.betaA, &c.betaB)
+	c.muReNeg = t1.Sign() < 0
+	muRe.Lsh(t1.Abs(&t1), 384).Quo(&muRe, &c.norm)
+	c.muImNeg = c.betaB.Sign() > 0
+	muIm.Lsh(t1.Abs(&c.betaB), 384).Quo(&muIm, &c.norm)
+	if muRe.BitLen() <= 256 && muIm.BitLen() <= 256 {
+		var mu signed256
+		bigToS256(&mu, &muRe)
+		c.muRe = [4]uint64{mu.w0, mu.w1, mu.w2, mu.w3}
+		bigToS256(&mu, &muIm)
+		c.muIm = [4]uint64{mu.w0, mu.w1, mu.w2, mu.w3}
+		c.fixedWidth = true
+	}
+
+	return c, nil
+}
+
+// Prime returns a primary associate of β.
+func (c *CubicSymbolContext) Prime() *ComplexNumber {
+	var z ComplexNumber
+	z.A0.Set(&c.betaA)
+	z.A1.Set(&c.betaB)
+	return &z
+}
+
+// Modulus returns p = N(β).
+func (c *CubicSymbolContext) Modulus() *big.Int {
+	return new(big.Int).Set(&c.norm)
+}
+
+// Omega returns the image of ω in 𝔽p ≅ ℤ[ω]/(β), i.e. -a/b mod p.
+func (c *CubicSymbolContext) Omega() *big.Int {
+	return new(big.Int).Set(&c.omega)
+}
+
+// Symbol returns the cubic residue symbol of x modulo β: 0 (symbol=1, cubic
+// residue, or x ≡ 0 mod p), 1 (symbol=ω), or 2 (symbol=ω²).
+func (c *CubicSymbolContext) Symbol(x *big.Int) uint8 {
+	if c.fixedWidth && x.Sign() >= 0 && x.BitLen() <= 384 {
+		var words [6]uint64
+		for i, w := range x.Bits() {
+			words[i] = uint64(w)
+		}
+		return c.SymbolWords(&words)
+	}
+
+	var r big.Int
+	r.Mod(x, &c.norm)
+	if r.Sign() == 0 {
+		return 0
+	}
+	e0, e1 := c.reduceBetaBig(&r)
+	if sym, ok := c.symbolFromRem(e0, e1); ok {
+		return sym
+	}
+	return c.fallback(&r)
+}
+
+// SymbolWords is like Symbol for 0 ≤ x < 2³⁸⁴ given as little-endian 64-bit
+// words, as returned by the Bits method of the fp.Element types. It doesn't
+// allocate when p > 2²⁵⁷.
+func (c *CubicSymbolContext) SymbolWords(x *[6]uint64) uint8 {
+	if !c.fixedWidth {
+		return c.Symbol(wordsToBig(x))
+	}
+	if x[0]|x[1]|x[2]|x[3]|x[4]|x[5] == 0 {
+		return 0
+	}
+
+	// Phase 1: compute first remainder = (x, 0) mod β
+	e0, e1 := c.reduceBeta(x)
+
+	if sym, ok := c.symbolFromRem(e0, e1); ok {
+		return sym
+	}
+	return c.fallback(wordsToBig(x))
+}
+
+// symbolsBlock is the number of remainders kept on the stack by SymbolsWords.
+const symbolsBlock = 32
+
+// SymbolsWords sets dst[i] to c.SymbolWords(&xs[i]) for all i. It runs the
+// fixed-width phase 1, which has no data-dependent branches, on a block of
+// inputs before running phase 2 on each of their remainders. It doesn't
+// allocate when p > 2²⁵⁷.
+//
+// It panics if len(dst) != len(xs).
+func (c *CubicSymbolContext) SymbolsWords(dst []uint8, xs [][6]uint64) {
+	if len(dst) != len(xs) {
+		panic("SymbolsWords: dst and xs don't have the same length")
+	}
+	if !c.fixedWidth {
+		for i := range xs {
+			dst[i] = c.SymbolWords(&xs[i])
+		}
+		return
+	}
+
+	var rems [symbolsBlock][2]signed256
+	for start := 0; start < len(xs); start += symbolsBlock {
+		block := xs[start:min(start+symbolsBlock, len(xs))]
+
+		// Phase 1: compute first remainders = (x, 0) mod β, zero for x = 0
+		for i := range block {
+			rems[i][0], rems[i][1] = c.reduceBeta(&block[i])
+		}
+
+		for i := range block {
+			sym, ok := c.symbolFromRem(rems[i][0], rems[i][1])
+			if !ok {
+				sym = c.fallback(wordsToBig(&block[i]))
+			}
+			dst[start+i] = sym
+		}
+	}
+}
+
+// symbolFromRem runs phase 2 of Symbol from the first remainder (e0, e1) of x
+// modulo β. It returns false if the GCD fails, in which case the symbol is
+// computed by fallback.
+func (c *CubicSymbolContext) symbolFromRem(e0, e1 signed256) (uint8, bool) {
+	if e0.isZero() && e1.isZero() {
+		return 0, true
+	}
+
+	// Process first remainder: remove (1-ω) factors and make primary
+	result := uint64(0)
+
+	m := uint64(0)
+	for (mod3_256(e0)+mod3_256(e1))%3 == 0 {
+		divBy1MinusOmega256(&e0, &e1)
+		m++
+	}
+	n := makePrimaryEis256(&e0, &e1)
+
+	corr := cubicCorrection(c.beta256A0, c.beta256A1, m, n)
+	if corr < 0 {
+		return 0, false
+	}
+	result = uint64(corr)
+
+	// Swap: a = β_orig, b = processed first remainder
+	a0, a1 := c.beta256A0, c.beta256A1
+	b0, b1 := e0, e1
+
+	// Phase 2a: signed256 Eisenstein GCD loop until components fit in 128 bits
+	for iter := 0; ; iter++ {
+		if iter > 300 {
+			return 0, false
+		}
+
+		if isRealUnit256(a0, a1) || isRealUnit256(b0, b1) {
+			return uint8(result), true
+		}
+
+		// Check if we can switch to the faster signed128 loop
+		if fitsIn128(a0, a1, b0, b1) {
+			return cubicGCD128(s256to128(a0), s256to128(a1), s256to128(b0), s256to128(b1), result)
+		}
+
+		e0, e1 = eisRem256(a0, a1, b0, b1)
+
+		if e0.isZero() && e1.isZero() {
+			return 0, true
+		}
+
+		m = 0
+		for (mod3_256(e0)+mod3_256(e1))%3 == 0 {
+			divBy1MinusOmega256(&e0, &e1)
+			m++
+		}
+
+		n = makePrimaryEis256(&e0, &e1)
+
+		corr = cubicCorrection(b0, b1, m, n)
+		if corr < 0 {
+			return 0, false
+		}
+		result = (result + uint64(corr)) % 3
+
+		a0, a1 = b0, b1
+		b0, b1 = e0, e1
+	}
+}
+
+// cubicGCD128 continues the Eisenstein GCD using signed128 arithmetic.
+func cubicGCD128(a0, a1, b0, b1 signed128, result uint64) (uint8, bool) {
+	for iter := 0; ; iter++ {
+		if iter > 200 {
+			return 0, false
+		}
+
+		if isRealUnit128(a0, a1) || isRealUnit128(b0, b1) {
+			break
+		}
+
+		e0, e1 := eisRem128(a0, a1, b0, b1)
+
+		if e0.isZero() && e1.isZero() {
+			return 0, true
+		}
+
+		m := uint64(0)
+		for (mod3_128(e0)+mod3_128(e1))%3 == 0 {
+			divBy1MinusOmega128(&e0, &e1)
+			m++
+		}
+
+		n := makePrimaryEis128(&e0, &e1)
+
+		corr := cubicCorrection128(b0, b1, m, n)
+		if corr < 0 {
+			return 0, false
+		}
+		result = (result + uint64(corr)) % 3
+
+		a0, a1 = b0, b1
+		b0, b1 = e0, e1
+	}
+
+	return uint8(result), true
+}
+
+// cubicCorrection computes the power-of-ω correction for one GCD step.
+// Given current denominator (b₀, b₁) and the (1-ω)-valuation m and primary-adjustment n,
+// returns the exponent of ω to add to the result (0, 1, or 2), or -1 on error.
+func cubicCorrection(b0, b1 signed256, m uint64, n int) int {
+	b0m9 := mod9_256(b0)
+	b1m9 := mod9_256(b1)
+	b0sqM9 := (b0m9 * b0m9) % 9
+	b0b1M9 := (b0m9 * b1m9) % 9
+	// ((1-ω)/β)₃ = ω^{(1-b₀²)/3}: termM = (1-b₀²) mod 9
+	termM := (1 + 9 - b0sqM9) % 9
+	// (ω/β)₃ = ω^{(b₀²-b₀b₁-1)/3}: termN = (b₀²-b₀b₁-1) mod 9
+	termN := (b0sqM9 + 18 - b0b1M9 - 1) % 9
+	q0m9 := (m%9*termM + uint64(n)*termN) % 9
+
+	switch q0m9 {
+	case 0:
+		return 0
+	case 3:
+		return 1
+	case 6:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// fallback uses the exponentiation-based cubic character.
+func (c *CubicSymbolContext) fallback(x *big.Int) uint8 {
+	var sym big.Int
+	sym.Exp(x, &c.exp, &c.norm)
+	if sym.Cmp(big.NewInt(1)) == 0 || sym.Sign() == 0 {
+		return 0
+	}
+	// x^((p-1)/3) ≡ [x/β]₃ mod β
+	if sym.Cmp(&c.omega) == 0 {
+		return 1
+	}
+	return 2
+}
+
+// --- Phase 1: reduction modulo β ---
+
+// reduceBeta returns a remainder (e0, e1) of x modulo β, using fixed-width
+// arithmetic.
+func (c *CubicSymbolContext) reduceBeta(x *[6]uint64) (e0, e1 signed256) {
+	qRe := cubicRoundQuo(x, &c.muRe)
+	qIm := cubicRoundQuo(x, &c.muIm)
+
+	// The remainder is smaller than 2²⁵⁵, so it is computed modulo 2²⁵⁶ in
+	// two's complement:
+	//   e = x - (q_re·a - q_im·b)
+	//   f = -(q_re·b + q_im·(a-b))
+	e := [4]uint64{x[0], x[1], x[2], x[3]}
+	e = sub4(e, mulLow4(&qRe, c.muReNeg, c.beta256A0))
+	e = add4(e, mulLow4(&qIm, c.muImNeg, c.beta256A1))
+	f := mulLow4(&qRe, c.muReNeg, c.beta256A1)
+	f = add4(f, mulLow4(&qIm, c.muImNeg, sub256(c.beta256A0, c.beta256A1)))
+	f = neg4(f)
+
+	return fromTwos4(e), fromTwos4(f)
+}
+
+// reduceBetaBig returns a remainder (e0, e1) of 0 ≤ x < p modulo β, using
+// big.Int arithmetic.
+func (c *CubicSymbolContext) reduceBetaBig(x *big.Int) (e0, e1 signed256) {
+	// q = round(x·conj(β)/p), conj(β) = (a-b) - b·ω
+	var qRe, qIm, t, u big.Int
+	t.Sub(&c.betaA, &c.betaB).Mul(&t, x)
+	roundQuo(&qRe, &t, &c.norm)
+	t.Mul(&c.betaB, x).Neg(&t)
+	roundQuo(&qIm, &t, &c.norm)
+
+	// e = x - (q_re·a - q_im·b)
+	// f = -(q_re·b + q_im·(a-b))
+	t.Mul(&qRe, &c.betaA)
+	u.Mul(&qIm, &c.betaB)
+	t.Sub(&t, &u).Sub(x, &t)
+	bigToS256(&e0, &t)
+	t.Mul(&qRe, &c.betaB)
+	u.Sub(&c.betaA, &c.betaB).Mul(&u, &qIm)
+	t.Add(&t, &u).Neg(&t)
+	bigToS256(&e1, &t)
+
+	return e0, e1
+}
+
+// roundQuo sets z to round(x/d) for d > 0.
+func roundQuo(z, x, d *big.Int) {
+	var t big.Int
+	t.Lsh(x, 1).Add(&t, d)
+	z.Lsh(d, 1)
+	z.Div(&t, z)
+}
+
+// cubicRoundQuo returns round(x·mu/2³⁸⁴), which must be smaller than 2²⁵⁶.
+func cubicRoundQuo(x *[6]uint64, mu *[4]uint64) [4]uint64 {
+	var t [10]uint64
+	for i := range x {
+		var carry uint64
+		for j := range mu {
+			hi, lo := bits.Mul64(x[i], mu[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j] = lo
+			carry = hi
+		}
+		t[i+4] = carry
+	}
+	// + 2³⁸³ to round to the nearest
+	var c uint64
+	t[5], c = bits.Add64(t[5], 1<<63, 0)
+	t[6], c = bits.Add64(t[6], 0, c)
+	t[7], c = bits.Add64(t[7], 0, c)
+	t[8], c = bits.Add64(t[8], 0, c)
+	t[9], _ = bits.Add64(t[9], 0, c)
+	return [4]uint64{t[6], t[7], t[8], t[9]}
+}
+
+// wordsToBig converts little-endian 64-bit words to a big.Int.
+func wordsToBig(x *[6]uint64) *big.Int {
+	w := make([]big.Word, len(x))
+	for i := range x {
+		w[i] = big.Word(x[i])
+	}
+	return new(big.Int).SetBits(w)
+}
@@ -0,0 +1,341 @@
+package eisenstein
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+// the Eisenstein primes of the cubic symbols of the curves, of norm p
+var curveBetas = []struct {
+	name string
+	a, b string
+}{
+	{"BLS12-381", "-1155048275357884106335086113613464118783412807316232579754", "1155048275357884106335086113613464118768280431093290937003"},
+	{"BLS12-377-STRONG", "-270099448789243659937786227511937374604623909586477951659", "270099448789243659937786227511937374595301067725730392747"},
+	{"BLS12-376-STRONG", "433386200905713772878563252435522861392305022889786430806", "216693100452856886439281626217761430700483883102737443499"},
+}
+
+func TestCubicSymbolContextSmallPrimes(t *testing.T) {
+	t.Parallel()
+
+	// all the Eisenstein primes of small norm, and all the residues
+	for a := int64(-12); a <= 12; a++ {
+		for b := int64(-12); b <= 12; b++ {
+			c, err := NewCubicSymbolContext(big.NewInt(a), big.NewInt(b))
+			if err != nil {
+				if !errors.Is(err, ErrInvalidPrime) {
+					t.Fatalf("%d+%dω: unexpected error %v", a, b, err)
+				}
+				continue
+			}
+			p := c.Modulus()
+			for x := int64(0); x < p.Int64(); x++ {
+				xb := big.NewInt(x)
+				if got, want := c.Symbol(xb), c.fallback(xb); got != want {
+					t.Fatalf("%d+%dω: symbol of %d is %d, expected %d", a, b, x, got, want)
+				}
+				// x + p and x - p
+				xb.Add(xb, p)
+				if got, want := c.Symbol(xb), c.fallback(big.NewInt(x)); got != want {
+					t.Fatalf("%d+%dω: symbol of %d+p is %d, expected %d", a, b, x, got, want)
+				}
+				xb.Sub(xb, p).Sub(xb, p)
+				if got, want := c.Symbol(xb), c.fallback(big.NewInt(x)); got != want {
+					t.Fatalf("%d+%dω: symbol of %d-p is %d, expected %d", a, b, x, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCubicSymbolContext(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genC := GenCubicSymbolContext()
+	genX := GenNumber(384)
+
+	properties.Property("Symbol should output same result as Exp by (p-1)/3", prop.ForAll(
+		func(c *CubicSymbolContext, x *big.Int) bool {
+			var r big.Int
+			r.Mod(x, &c.norm)
+			return c.Symbol(x) == c.fallback(&r)
+		},
+		genC,
+		genX,
+	))
+
+	properties.Property("Symbol should be multiplicative", prop.ForAll(
+		func(c *CubicSymbolContext, x, y *big.Int) bool {
+			var xy big.Int
+			xy.Mul(x, y)
+			return c.Symbol(&xy) == (c.Symbol(x)+c.Symbol(y))%3
+		},
+		genC,
+		genX,
+		genX,
+	))
+
+	properties.Property("Symbol should be the same for the associates of β", prop.ForAll(
+		func(c *CubicSymbolContext, x *big.Int) bool {
+			// -ω·β = b + (b-a)·ω
+			var a, b big.Int
+			a.Set(&c.betaB)
+			b.Sub(&c.betaB, &c.betaA)
+			c2, err := NewCubicSymbolContext(&a, &b)
+			return err == nil && c2.Prime().Equal(c.Prime()) &&
+				c2.Omega().Cmp(c.Omega()) == 0 && c2.Symbol(x) == c.Symbol(x)
+		},
+		genC,
+		genX,
+	))
+
+	properties.Property("SymbolsWords should output same result as SymbolWords", prop.ForAll(
+		func(c *CubicSymbolContext, x *big.Int) bool {
+			// more than two blocks of x, x², … modulo p, and 0
+			xs := make([][6]uint64, 2*symbolsBlock+3)
+			var xi big.Int
+			xi.Set(x)
+			for i := 1; i < len(xs); i++ {
+				for j, w := range xi.Bits() {
+					xs[i][j] = uint64(w)
+				}
+				xi.Mul(&xi, x).Mod(&xi, &c.norm)
+			}
+
+			dst := make([]uint8, len(xs))
+			c.SymbolsWords(dst, xs)
+			for i := range xs {
+				if dst[i] != c.SymbolWords(&xs[i]) {
+					return false
+				}
+			}
+			return true
+		},
+		genC,
+		genX,
+	))
+
+	properties.Property("Omega should be a primitive third root of unity of symbol ω^((p-1)/3)", prop.ForAll(
+		func(c *CubicSymbolContext) bool {
+			var t big.Int
+			omega := c.Omega()
+			t.Mul(omega, omega).Add(&t, omega).Add(&t, big.NewInt(1)).Mod(&t, &c.norm)
+			if t.Sign() != 0 {
+				return false
+			}
+			// [ω/β]₃ = ω^((p-1)/3)
+			return uint64(c.Symbol(omega)) == t.Mod(&c.exp, big.NewInt(3)).Uint64()
+		},
+		genC,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestCubicSymbolContextCurves(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	for _, beta := range curveBetas {
+		var a, b big.Int
+		a.SetString(beta.a, 10)
+		b.SetString(beta.b, 10)
+		c, err := NewCubicSymbolContext(&a, &b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.fixedWidth {
+			t.Fatalf("%s: expected a fixed-width phase 1", beta.name)
+		}
+
+		properties := gopter.NewProperties(parameters)
+
+		genX := GenNumber(384)
+
+		properties.Property("["+beta.name+"] SymbolWords should output same result as Exp by (p-1)/3", prop.ForAll(
+			func(x *big.Int) bool {
+				var r big.Int
+				r.Mod(x, &c.norm)
+				var words [6]uint64
+				for i, w := range x.Bits() {
+					words[i] = uint64(w)
+				}
+				return c.SymbolWords(&words) == c.fallback(&r)
+			},
+			genX,
+		))
+
+		properties.Property("["+beta.name+"] reduceBeta should output a remainder of x modulo β", prop.ForAll(
+			func(x *big.Int) bool {
+				var words [6]uint64
+				for i, w := range x.Bits() {
+					words[i] = uint64(w)
+				}
+				e0, e1 := c.reduceBeta(&words)
+				return isRemainder(c, x, e0, e1)
+			},
+			genX,
+		))
+
+		properties.Property("["+beta.name+"] reduceBetaBig should output a remainder of x modulo β", prop.ForAll(
+			func(x *big.Int) bool {
+				var r big.Int
+				r.Mod(x, &c.norm)
+				e0, e1 := c.reduceBetaBig(&r)
+				return isRemainder(c, &r, e0, e1)
+			},
+			genX,
+		))
+
+		properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+		// multiples of p
+		for _, x := range []*big.Int{new(big.Int), c.Modulus(), new(big.Int).Lsh(c.Modulus(), 2)} {
+			if sym := c.Symbol(x); sym != 0 {
+				t.Fatalf("%s: symbol of %v is %d, expected 0", beta.name, x, sym)
+			}
+		}
+	}
+}
+
+func TestNewCubicSymbolContextErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, beta := range []struct {
+		a, b int64
+		err  error
+	}{
+		{0, 0, ErrInvalidPrime},
+		{1, -1, ErrInvalidPrime}, // 1-ω, of norm 3
+		{2, 0, ErrInvalidPrime},  // 2 is inert
+		{4, 2, ErrInvalidPrime},  // norm 12
+	} {
+		if _, err := NewCubicSymbolContext(big.NewInt(beta.a), big.NewInt(beta.b)); !errors.Is(err, beta.err) {
+			t.Fatalf("%d+%dω: expected %v, got %v", beta.a, beta.b, beta.err, err)
+		}
+	}
+
+	var a big.Int
+	a.Lsh(big.NewInt(1), 193)
+	if _, err := NewCubicSymbolContext(&a, big.NewInt(3)); !errors.Is(err, ErrPrimeTooLarge) {
+		t.Fatalf("expected ErrPrimeTooLarge, got %v", err)
+	}
+}
+
+// isRemainder checks that β divides x - (e0 + e1·ω) and that e0 and e1 are
+// smaller than 2²⁵⁵.
+func isRemainder(c *CubicSymbolContext, x *big.Int, e0, e1 signed256) bool {
+	var u, v big.Int
+	u.Sub(x, s256ToBig(e0))
+	v.Neg(s256ToBig(e1))
+
+	// β | u+vω iff p divides both components of (u+vω)·conj(β):
+	// u(a-b)+vb and va-ub.
+	var re, im, t big.Int
+	t.Sub(&c.betaA, &c.betaB)
+	re.Mul(&u, &t).Add(&re, t.Mul(&v, &c.betaB))
+	im.Mul(&v, &c.betaA).Sub(&im, t.Mul(&u, &c.betaB))
+	return e0.w3>>63 == 0 && e1.w3>>63 == 0 &&
+		t.Mod(&re, &c.norm).Sign() == 0 && t.Mod(&im, &c.norm).Sign() == 0
+}
+
+// s256ToBig converts s to a big.Int.
+func s256ToBig(s signed256) *big.Int {
+	var z big.Int
+	z.SetBits([]big.Word{big.Word(s.w0), big.Word(s.w1), big.Word(s.w2), big.Word(s.w3)})
+	if s.neg {
+		z.Neg(&z)
+	}
+	return &z
+}
+
+// GenCubicSymbolContext generates a context for a random Eisenstein prime of
+// norm between 2⁸ and 2³⁸⁴
+func GenCubicSymbolContext() gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		size := 4 + genParams.Rng.Intn(188)
+		var bound big.Int
+		bound.Lsh(big.NewInt(1), uint(size))
+		for {
+			a, _ := rand.Int(genParams.Rng, &bound)
+			b, _ := rand.Int(genParams.Rng, &bound)
+			if genParams.Rng.Intn(2) == 0 {
+				a.Neg(a)
+			}
+			if c, err := NewCubicSymbolContext(a, b); err == nil {
+				return gopter.NewGenResult(c, gopter.NoShrinker)
+			}
+		}
+	}
+}
+
+// bench
+func BenchmarkCubicSymbolContext(b *testing.B) {
+	var a, bb big.Int
+	a.SetString(curveBetas[0].a, 10)
+	bb.SetString(curveBetas[0].b, 10)
+	c, _ := NewCubicSymbolContext(&a, &bb)
+
+	var x big.Int
+	x.SetString("2929494998551518193999723405412053246602204569353345363675794262224468384146017685416659704346369932930853282892458", 10)
+	var words [6]uint64
+	for i, w := range x.Bits() {
+		words[i] = uint64(w)
+	}
+
+	b.Run("Symbol", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			c.Symbol(&x)
+		}
+	})
+	b.Run("SymbolWords", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			c.SymbolWords(&words)
+		}
+	})
+	b.Run("SymbolsWords", func(b *testing.B) {
+		xs := make([][6]uint64, 1<<10)
+		for i := range xs {
+			xs[i] = words
+			xs[i][0] += uint64(i)
+		}
+		dst := make([]uint8, len(xs))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for j := 0; j < b.N; j++ {
+			c.SymbolsWords(dst, xs)
+		}
+	})
+	b.Run("Phase1", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			c.reduceBeta(&words)
+		}
+	})
+	b.Run("Phase1Big", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			c.reduceBetaBig(&x)
+		}
+	})
+}
@@ -6,4 +6,18 @@
 // of unity i.e. ω²+ω+1 = 0.
 //
 // This is from gnark-crypto but faster.
+//
+// CubicSymbolContext computes cubic residue symbols modulo a fixed Eisenstein
+// prime with a fixed-width Eisenstein GCD. The curve packages only provide
+// their prime.
+//
+// Int256 and Int512 are allocation-free value types for Eisenstein integers
+// of fixed width, with overflow-checked arithmetic. ComplexNumber remains the
+// arbitrary-precision type.
+//
+// ComplexNumber also provides the Euclidean Mod, GCD, ExtendedGCD and Exp, and
+// FactorPrime splits a rational prime into Eisenstein primes.
+//
+// ComplexNumber is parsed by SetString, and implements fmt.Formatter and the
+// text, JSON and binary marshalers.
 package eisenstein
@@ -10,6 +10,7 @@ import (
 type ComplexNumber struct {
 	A0, A1         big.Int
 	t0, t1, t2, t3 big.Int    // temporary variables
+	n              big.Int    // norm of the divisor in Quo
 	_              sync.Mutex // to ensure there is no accidental value copy
 }
 
@@ -190,14 +191,14 @@ func (z *ComplexNumber) roundNearest(num *ComplexNumber, d *big.Int) {
 // and guarantees ‖r‖ < ‖y‖ (true Euclidean division in ℤ[ω]).
 func (z *ComplexNumber) Quo(x, y *ComplexNumber) *ComplexNumber {
 
-	// x.t0 = Norm(y)
-	y.Norm(&x.t0)
+	// z.n = Norm(y), which is not overwritten by z = x * ȳ if z is x or y
+	y.Norm(&z.n)
 
 	// z = x * ȳ
 	z.MulByConjugate(x, y)
 
 	// rounding of both coordinates
-	z.roundNearest(z, &x.t0)
+	z.roundNearest(z, &z.n)
 
 	return z
 }
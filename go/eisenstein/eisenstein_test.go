@@ -88,6 +88,111 @@ func TestEisensteinReceiverIsOperand(t *testing.T) {
 		genE,
 	))
 
+	properties.Property("Having the receiver as operand (quo) should output the same result", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			if b.A0.Sign() == 0 && b.A1.Sign() == 0 {
+				return true
+			}
+			var c, d ComplexNumber
+			d.Set(a)
+			c.Quo(a, b)
+			a.Quo(a, b)
+			b.Quo(&d, b)
+			return a.Equal(b) && a.Equal(&c) && b.Equal(&c)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Having the receiver as both operands (quo) should output one", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			if a.A0.Sign() == 0 && a.A1.Sign() == 0 {
+				return true
+			}
+			var one ComplexNumber
+			one.SetOne()
+			a.Quo(a, a)
+			return a.Equal(&one)
+		},
+		genE,
+	))
+
+	properties.Property("Having the receiver as operand (mod) should output the same result", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			if b.A0.Sign() == 0 && b.A1.Sign() == 0 {
+				return true
+			}
+			var c, d ComplexNumber
+			d.Set(a)
+			c.Mod(a, b)
+			a.Mod(a, b)
+			b.Mod(&d, b)
+			return a.Equal(b) && a.Equal(&c) && b.Equal(&c)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Having the receiver as operand (gcd) should output the same result", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c, d ComplexNumber
+			d.Set(a)
+			c.GCD(a, b)
+			a.GCD(a, b)
+			b.GCD(&d, b)
+			return a.Equal(b) && a.Equal(&c) && b.Equal(&c)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Having the receiver as operand (extended gcd) should output the same result", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c, s, t, d, e, s1, t1 ComplexNumber
+			d.Set(a)
+			e.Set(b)
+			c.ExtendedGCD(&s, &t, a, b)
+			a.ExtendedGCD(&s1, &t1, a, b)
+			if !a.Equal(&c) || !s1.Equal(&s) || !t1.Equal(&t) {
+				return false
+			}
+			// s and t as operands
+			s1.Set(&d)
+			t1.Set(&e)
+			b.ExtendedGCD(&s1, &t1, &s1, &t1)
+			return b.Equal(&c) && s1.Equal(&s) && t1.Equal(&t)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Having the receiver as operand (exp) should output the same result", prop.ForAll(
+		func(a, m *ComplexNumber, k uint8) bool {
+			var b, c, d ComplexNumber
+			e := big.NewInt(int64(k))
+			d.Set(a)
+			b.Exp(a, e, m)
+			a.Exp(a, e, m)
+			m.Exp(&d, e, m)
+			c.Exp(&d, e, nil)
+			d.Exp(&d, e, nil)
+			return a.Equal(&b) && m.Equal(&b) && d.Equal(&c)
+		},
+		genE,
+		genE,
+		genUint8(),
+	))
+
+	properties.Property("Having the receiver as operand (normalize) should output the same result", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			k1 := b.Normalize(a)
+			k2 := a.Normalize(a)
+			return k1 == k2 && a.Equal(&b)
+		},
+		genE,
+	))
+
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
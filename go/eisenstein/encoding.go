@@ -0,0 +1,169 @@
+package eisenstein
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	// ErrSyntax means that a string is not an Eisenstein integer as formatted
+	// by String or Format.
+	ErrSyntax = errors.New("invalid Eisenstein integer syntax")
+
+	// ErrInvalidEncoding means that a binary encoding of an Eisenstein integer
+	// is invalid.
+	ErrInvalidEncoding = errors.New("invalid Eisenstein integer encoding")
+
+	// ErrNilNumber means that a nil *ComplexNumber is marshaled to text or
+	// binary, which have no encoding of nil. MarshalJSON encodes it as null.
+	ErrNilNumber = errors.New("nil Eisenstein integer")
+)
+
+// SetString sets z to the value of s, in the format a+(b*ω) of String, and
+// returns z. The coordinates a and b are parsed by big.Int.SetString with base
+// 0, so that they accept the prefixes 0b, 0o, 0 and 0x as output by Format
+// with the # flag.
+//
+// If s is invalid, z is not modified and SetString returns nil and an error
+// wrapping ErrSyntax.
+func (z *ComplexNumber) SetString(s string) (*ComplexNumber, error) {
+	a, rest, ok := strings.Cut(s, "+(")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	b, ok := strings.CutSuffix(rest, "*ω)")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	if _, ok := z.t0.SetString(a, 0); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	if _, ok := z.t1.SetString(b, 0); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	z.A0.Set(&z.t0)
+	z.A1.Set(&z.t1)
+	return z, nil
+}
+
+// Format implements fmt.Formatter. The verbs %v and %s print z as String
+// does. The verbs %b, %o, %O, %d, %x and %X print both coordinates in the
+// format a+(b*ω) with the verb and flags of big.Int.Format, e.g. %#x prints
+// 0x1f+(-0x2*ω), which SetString parses back.
+func (z *ComplexNumber) Format(s fmt.State, verb rune) {
+	if z == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+	switch verb {
+	case 'v', 's':
+		fmt.Fprint(s, z.String())
+	case 'b', 'o', 'O', 'd', 'x', 'X':
+		format := fmt.FormatString(s, verb)
+		fmt.Fprintf(s, format+"+("+format+"*ω)", &z.A0, &z.A1)
+	default:
+		fmt.Fprintf(s, "%%!%c(*eisenstein.ComplexNumber=%s)", verb, z.String())
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, with the format of String.
+// It returns ErrNilNumber if z is nil.
+func (z *ComplexNumber) MarshalText() ([]byte, error) {
+	if z == nil {
+		return nil, ErrNilNumber
+	}
+	return []byte(z.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see SetString.
+func (z *ComplexNumber) UnmarshalText(text []byte) error {
+	_, err := z.SetString(string(text))
+	return err
+}
+
+// MarshalJSON implements json.Marshaler. z is encoded as a JSON string in the
+// format of String, or null if z is nil.
+func (z *ComplexNumber) MarshalJSON() ([]byte, error) {
+	if z == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(z.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. null leaves z unchanged.
+func (z *ComplexNumber) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	_, err := z.SetString(s)
+	return err
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Each coordinate is
+// encoded as a sign byte (0 for x ≥ 0, 1 for x < 0), the length of |x| in
+// bytes as a big-endian uint32, and |x| in big-endian without leading zeros.
+// It returns ErrNilNumber if z is nil.
+func (z *ComplexNumber) MarshalBinary() ([]byte, error) {
+	if z == nil {
+		return nil, ErrNilNumber
+	}
+	data := make([]byte, 0, 10+(z.A0.BitLen()+7)/8+(z.A1.BitLen()+7)/8)
+	for _, x := range []*big.Int{&z.A0, &z.A1} {
+		var sign byte
+		if x.Sign() < 0 {
+			sign = 1
+		}
+		data = append(data, sign)
+		data = binary.BigEndian.AppendUint32(data, uint32((x.BitLen()+7)/8))
+		data = appendAbs(data, x)
+	}
+	return data, nil
+}
+
+// appendAbs appends |x| in big-endian without leading zeros to data.
+func appendAbs(data []byte, x *big.Int) []byte {
+	n := (x.BitLen() + 7) / 8
+	data = append(data, make([]byte, n)...)
+	x.FillBytes(data[len(data)-n:])
+	return data
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, see MarshalBinary.
+// It only accepts the encoding output by MarshalBinary, and returns
+// ErrInvalidEncoding otherwise, in which case z is not modified.
+func (z *ComplexNumber) UnmarshalBinary(data []byte) error {
+	for _, x := range []*big.Int{&z.t0, &z.t1} {
+		if len(data) < 5 || data[0] > 1 {
+			return ErrInvalidEncoding
+		}
+		neg := data[0] == 1
+		n := binary.BigEndian.Uint32(data[1:5])
+		data = data[5:]
+		if uint64(len(data)) < uint64(n) {
+			return ErrInvalidEncoding
+		}
+		// no leading zeros, and no negative zero
+		if (n > 0 && data[0] == 0) || (n == 0 && neg) {
+			return ErrInvalidEncoding
+		}
+		x.SetBytes(data[:n])
+		if neg {
+			x.Neg(x)
+		}
+		data = data[n:]
+	}
+	if len(data) != 0 {
+		return ErrInvalidEncoding
+	}
+	z.A0.Set(&z.t0)
+	z.A1.Set(&z.t1)
+	return nil
+}
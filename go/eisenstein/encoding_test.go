@@ -0,0 +1,157 @@
+package eisenstein
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestEncoding(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(384)
+
+	properties.Property("SetString should parse String", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			_, err := b.SetString(a.String())
+			return err == nil && b.Equal(a)
+		},
+		genE,
+	))
+
+	properties.Property("SetString should parse Format with the # flag", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			for _, format := range []string{"%v", "%s", "%d", "%+d", "%#b", "%#o", "%O", "%#x", "%#X"} {
+				var b ComplexNumber
+				if _, err := b.SetString(fmt.Sprintf(format, a)); err != nil || !b.Equal(a) {
+					return false
+				}
+			}
+			return fmt.Sprintf("%d", a) == a.String() &&
+				fmt.Sprintf("%x", a) == fmt.Sprintf("%x+(%x*ω)", &a.A0, &a.A1)
+		},
+		genE,
+	))
+
+	properties.Property("UnmarshalText should invert MarshalText", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			text, err := a.MarshalText()
+			return err == nil && b.UnmarshalText(text) == nil && b.Equal(a)
+		},
+		genE,
+	))
+
+	properties.Property("UnmarshalJSON should invert MarshalJSON", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			type vector struct {
+				Beta  *ComplexNumber
+				Alpha []*ComplexNumber
+			}
+			data, err := json.Marshal(vector{Beta: a, Alpha: []*ComplexNumber{b, a}})
+			if err != nil {
+				return false
+			}
+			var v vector
+			return json.Unmarshal(data, &v) == nil && v.Beta.Equal(a) &&
+				len(v.Alpha) == 2 && v.Alpha[0].Equal(b) && v.Alpha[1].Equal(a)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("UnmarshalBinary should invert MarshalBinary", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			data, err := a.MarshalBinary()
+			return err == nil && b.UnmarshalBinary(data) == nil && b.Equal(a)
+		},
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	var a, zero ComplexNumber
+	for _, s := range []string{"", "1", "1+(2*ω", "1+(2)", "1+(2*i)", "x+(2*ω)", "1+(0x*ω)", "1 +(2*ω)", "1+(2*ω) "} {
+		if _, err := a.SetString(s); !errors.Is(err, ErrSyntax) || !a.Equal(&zero) {
+			t.Fatalf("%q: expected ErrSyntax, got %v and %v", s, err, &a)
+		}
+	}
+	for _, data := range [][]byte{
+		nil,
+		{0, 0, 0, 0, 0},                   // missing A1
+		{2, 0, 0, 0, 0, 0, 0, 0, 0, 0},    // invalid sign
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0},    // -0
+		{0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0}, // leading zero
+		{0, 0, 0, 0, 2, 1, 0, 0, 0, 0, 0}, // truncated
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, // trailing byte
+	} {
+		if err := a.UnmarshalBinary(data); !errors.Is(err, ErrInvalidEncoding) || !a.Equal(&zero) {
+			t.Fatalf("%x: expected ErrInvalidEncoding, got %v and %v", data, err, &a)
+		}
+	}
+	var nilNumber *ComplexNumber
+	if s := fmt.Sprintf("%v %x %q", nilNumber, &a, &a); s != "<nil> 0+(0*ω) %!q(*eisenstein.ComplexNumber=0+(0*ω))" {
+		t.Fatalf("unexpected format %s", s)
+	}
+
+	// nil has no text or binary encoding, and is null in JSON
+	if _, err := nilNumber.MarshalText(); !errors.Is(err, ErrNilNumber) {
+		t.Fatalf("expected ErrNilNumber, got %v", err)
+	}
+	if _, err := nilNumber.MarshalBinary(); !errors.Is(err, ErrNilNumber) {
+		t.Fatalf("expected ErrNilNumber, got %v", err)
+	}
+	if data, err := json.Marshal(nilNumber); err != nil || string(data) != "null" {
+		t.Fatalf("expected null, got %s and %v", data, err)
+	}
+}
+
+func FuzzSetString(f *testing.F) {
+	for _, s := range []string{"0+(0*ω)", "-3+(5*ω)", "0x1f+(-0b11*ω)", "1+(2*ω", "+(*ω)"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		var a, b ComplexNumber
+		if _, err := a.SetString(s); err != nil {
+			return
+		}
+		if _, err := b.SetString(a.String()); err != nil || !b.Equal(&a) {
+			t.Fatalf("%q: %v does not round-trip: %v", s, &a, err)
+		}
+	})
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	var a ComplexNumber
+	a.A0.SetInt64(-300)
+	a.A1.SetInt64(7)
+	data, _ := a.MarshalBinary()
+	f.Add(data)
+	f.Add([]byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 2})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var a ComplexNumber
+		if err := a.UnmarshalBinary(data); err != nil {
+			return
+		}
+		// the encoding is canonical
+		got, err := a.MarshalBinary()
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("%x: %v is encoded as %x", data, &a, got)
+		}
+	})
+}
@@ -0,0 +1,504 @@
+package eisenstein
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Fixed-width signed integers for the Eisenstein GCD of CubicSymbolContext.
+// The components of the remainders of an Eisenstein prime of norm smaller than
+// 2³⁸⁴ fit in signed256, and in signed128 once they are smaller than 2⁹⁶.
+
+// 3⁻¹ mod 2⁶⁴, used for exact division by 3 via multiplication.
+const inv3mod264 = 0xAAAAAAAAAAAAAAAB
+
+// signed256 represents a signed 256-bit integer using 4 uint64 words.
+type signed256 struct {
+	w0, w1, w2, w3 uint64
+	neg            bool
+}
+
+func (s signed256) isZero() bool {
+	return s.w0 == 0 && s.w1 == 0 && s.w2 == 0 && s.w3 == 0
+}
+
+func neg256(s signed256) signed256 {
+	if s.isZero() {
+		return s
+	}
+	return signed256{s.w0, s.w1, s.w2, s.w3, !s.neg}
+}
+
+func cmpAbs256(a, b signed256) int {
+	if a.w3 != b.w3 {
+		if a.w3 > b.w3 {
+			return 1
+		}
+		return -1
+	}
+	if a.w2 != b.w2 {
+		if a.w2 > b.w2 {
+			return 1
+		}
+		return -1
+	}
+	if a.w1 != b.w1 {
+		if a.w1 > b.w1 {
+			return 1
+		}
+		return -1
+	}
+	if a.w0 != b.w0 {
+		if a.w0 > b.w0 {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+func add256(a, b signed256) signed256 {
+	if a.neg == b.neg {
+		w0, c := bits.Add64(a.w0, b.w0, 0)
+		w1, c := bits.Add64(a.w1, b.w1, c)
+		w2, c := bits.Add64(a.w2, b.w2, c)
+		w3, _ := bits.Add64(a.w3, b.w3, c)
+		return signed256{w0, w1, w2, w3, a.neg}
+	}
+	if cmpAbs256(a, b) >= 0 {
+		w0, bw := bits.Sub64(a.w0, b.w0, 0)
+		w1, bw := bits.Sub64(a.w1, b.w1, bw)
+		w2, bw := bits.Sub64(a.w2, b.w2, bw)
+		w3, _ := bits.Sub64(a.w3, b.w3, bw)
+		return signed256{w0, w1, w2, w3, a.neg}
+	}
+	w0, bw := bits.Sub64(b.w0, a.w0, 0)
+	w1, bw := bits.Sub64(b.w1, a.w1, bw)
+	w2, bw := bits.Sub64(b.w2, a.w2, bw)
+	w3, _ := bits.Sub64(b.w3, a.w3, bw)
+	return signed256{w0, w1, w2, w3, b.neg}
+}
+
+func sub256(a, b signed256) signed256 { return add256(a, neg256(b)) }
+
+func mulSmall256(s signed256, k int64) signed256 {
+	neg := s.neg
+	if k < 0 {
+		neg = !neg
+		k = -k
+	}
+	if k == 0 {
+		return signed256{}
+	}
+	uk := uint64(k)
+	h0, l0 := bits.Mul64(s.w0, uk)
+	h1, l1 := bits.Mul64(s.w1, uk)
+	h2, l2 := bits.Mul64(s.w2, uk)
+	_, l3 := bits.Mul64(s.w3, uk)
+
+	w0 := l0
+	w1, c := bits.Add64(l1, h0, 0)
+	w2, c := bits.Add64(l2, h1, c)
+	w3, _ := bits.Add64(l3, h2, c)
+
+	return signed256{w0, w1, w2, w3, neg}
+}
+
+func s256ToFloat(s signed256) float64 {
+	f := float64(s.w3)*0x1p192 + float64(s.w2)*0x1p128 + float64(s.w1)*0x1p64 + float64(s.w0)
+	if s.neg {
+		f = -f
+	}
+	return f
+}
+
+// mod3_256 returns s mod 3 in [0,2]. Since 2^64 ≡ 1 mod 3, sum all words mod 3.
+func mod3_256(s signed256) uint64 {
+	v := (s.w0%3 + s.w1%3 + s.w2%3 + s.w3%3) % 3
+	if s.neg && v != 0 {
+		v = 3 - v
+	}
+	return v
+}
+
+// mod9_256 returns s mod 9 in [0,8].
+// 2^64 ≡ 7 mod 9, 2^128 ≡ 4 mod 9, 2^192 ≡ 1 mod 9.
+func mod9_256(s signed256) uint64 {
+	v := (s.w0%9 + 7*(s.w1%9) + 4*(s.w2%9) + s.w3%9) % 9
+	if s.neg && v != 0 {
+		v = 9 - v
+	}
+	return v
+}
+
+// divExact3_256 divides s by 3 exactly using multiplicative inverse.
+func divExact3_256(s signed256) signed256 {
+	q0 := s.w0 * inv3mod264
+	c0, _ := bits.Mul64(q0, 3)
+
+	sub1, borrow1 := bits.Sub64(s.w1, c0, 0)
+	q1 := sub1 * inv3mod264
+	c1, _ := bits.Mul64(q1, 3)
+	c1 += borrow1
+
+	sub2, borrow2 := bits.Sub64(s.w2, c1, 0)
+	q2 := sub2 * inv3mod264
+	c2, _ := bits.Mul64(q2, 3)
+	c2 += borrow2
+
+	sub3, _ := bits.Sub64(s.w3, c2, 0)
+	q3 := sub3 * inv3mod264
+
+	return signed256{q0, q1, q2, q3, s.neg}
+}
+
+func bigToS256(s *signed256, x *big.Int) {
+	s.neg = x.Sign() < 0
+	w := x.Bits()
+	s.w0, s.w1, s.w2, s.w3 = 0, 0, 0, 0
+	if len(w) > 0 {
+		s.w0 = uint64(w[0])
+	}
+	if len(w) > 1 {
+		s.w1 = uint64(w[1])
+	}
+	if len(w) > 2 {
+		s.w2 = uint64(w[2])
+	}
+	if len(w) > 3 {
+		s.w3 = uint64(w[3])
+	}
+}
+
+// --- signed128 type and arithmetic (for later GCD iterations) ---
+
+type signed128 struct {
+	lo, hi uint64
+	neg    bool
+}
+
+func (s signed128) isZero() bool { return s.lo == 0 && s.hi == 0 }
+
+func neg128(s signed128) signed128 {
+	if s.isZero() {
+		return s
+	}
+	return signed128{s.lo, s.hi, !s.neg}
+}
+
+func cmpAbs128(a, b signed128) int {
+	if a.hi != b.hi {
+		if a.hi > b.hi {
+			return 1
+		}
+		return -1
+	}
+	if a.lo != b.lo {
+		if a.lo > b.lo {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+func add128(a, b signed128) signed128 {
+	if a.neg == b.neg {
+		lo, c := bits.Add64(a.lo, b.lo, 0)
+		hi, _ := bits.Add64(a.hi, b.hi, c)
+		return signed128{lo, hi, a.neg}
+	}
+	if cmpAbs128(a, b) >= 0 {
+		lo, bw := bits.Sub64(a.lo, b.lo, 0)
+		hi, _ := bits.Sub64(a.hi, b.hi, bw)
+		return signed128{lo, hi, a.neg}
+	}
+	lo, bw := bits.Sub64(b.lo, a.lo, 0)
+	hi, _ := bits.Sub64(b.hi, a.hi, bw)
+	return signed128{lo, hi, b.neg}
+}
+
+func sub128(a, b signed128) signed128 { return add128(a, neg128(b)) }
+
+func mulSmall128(s signed128, k int64) signed128 {
+	neg := s.neg
+	if k < 0 {
+		neg = !neg
+		k = -k
+	}
+	if k == 0 {
+		return signed128{}
+	}
+	uk := uint64(k)
+	hi, lo := bits.Mul64(s.lo, uk)
+	hi += s.hi * uk
+	return signed128{lo, hi, neg}
+}
+
+func s128ToFloat(s signed128) float64 {
+	f := float64(s.hi)*0x1p64 + float64(s.lo)
+	if s.neg {
+		f = -f
+	}
+	return f
+}
+
+// mod3_128 returns s mod 3 in [0,2]. Since 2^64 ≡ 1 mod 3, sum words mod 3.
+func mod3_128(s signed128) uint64 {
+	v := (s.lo%3 + s.hi%3) % 3
+	if s.neg && v != 0 {
+		v = 3 - v
+	}
+	return v
+}
+
+// mod9_128 returns s mod 9 in [0,8]. 2^64 ≡ 7 mod 9.
+func mod9_128(s signed128) uint64 {
+	v := (s.lo%9 + 7*(s.hi%9)) % 9
+	if s.neg && v != 0 {
+		v = 9 - v
+	}
+	return v
+}
+
+// divExact3_128 divides s by 3 exactly using multiplicative inverse.
+func divExact3_128(s signed128) signed128 {
+	q0 := s.lo * inv3mod264
+	c0, _ := bits.Mul64(q0, 3)
+	sub1, _ := bits.Sub64(s.hi, c0, 0)
+	q1 := sub1 * inv3mod264
+	return signed128{q0, q1, s.neg}
+}
+
+func isRealUnit128(re, im signed128) bool {
+	return im.isZero() && re.hi == 0 && re.lo <= 1
+}
+
+// Eisenstein arithmetic with signed128
+
+func eisQuotient128(a0, a1, b0, b1 signed128) (int64, int64) {
+	af0 := s128ToFloat(a0)
+	af1 := s128ToFloat(a1)
+	bf0 := s128ToFloat(b0)
+	bf1 := s128ToFloat(b1)
+
+	nB := bf0*bf0 + bf1*bf1 - bf0*bf1
+	if nB == 0 {
+		return 0, 0
+	}
+
+	numRe := af0*bf0 - af0*bf1 + af1*bf1
+	numIm := af1*bf0 - af0*bf1
+
+	return cubicRoundFloat(numRe / nB), cubicRoundFloat(numIm / nB)
+}
+
+func eisRem128(a0, a1, b0, b1 signed128) (signed128, signed128) {
+	qr, qi := eisQuotient128(a0, a1, b0, b1)
+	qb0 := sub128(mulSmall128(b0, qr), mulSmall128(b1, qi))
+	qb1 := sub128(add128(mulSmall128(b1, qr), mulSmall128(b0, qi)), mulSmall128(b1, qi))
+	return sub128(a0, qb0), sub128(a1, qb1)
+}
+
+func divBy1MinusOmega128(e, f *signed128) {
+	twoEMinusF := sub128(mulSmall128(*e, 2), *f)
+	sum := add128(*e, *f)
+	*e = divExact3_128(twoEMinusF)
+	*f = divExact3_128(sum)
+}
+
+func makePrimaryEis128(e, f *signed128) int {
+	r0 := mod3_128(*e)
+	// mod3(e-f) = (mod3(e) + 3 - mod3(f)) % 3
+	r1 := (r0 + 3 - mod3_128(*f)) % 3
+
+	if r0 == 0 {
+		newE := sub128(*f, *e)
+		newF := neg128(*e)
+		*e = newE
+		*f = newF
+		return 1
+	}
+	if r1 == 0 {
+		newE := neg128(*f)
+		newF := sub128(*e, *f)
+		*e = newE
+		*f = newF
+		return 2
+	}
+	return 0
+}
+
+func cubicCorrection128(b0, b1 signed128, m uint64, n int) int {
+	b0m9 := mod9_128(b0)
+	b1m9 := mod9_128(b1)
+	b0sqM9 := (b0m9 * b0m9) % 9
+	b0b1M9 := (b0m9 * b1m9) % 9
+	termM := (1 + 9 - b0sqM9) % 9
+	termN := (b0sqM9 + 18 - b0b1M9 - 1) % 9
+	q0m9 := (m%9*termM + uint64(n)*termN) % 9
+
+	switch q0m9 {
+	case 0:
+		return 0
+	case 3:
+		return 1
+	case 6:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// fitsIn128 returns true if all four signed256 values safely fit in signed128.
+// We require components < 2^96 (w1 < 2^32) to leave headroom for small
+// multiplications (by quotient components ≤ 4) inside the signed128 GCD loop.
+func fitsIn128(a0, a1, b0, b1 signed256) bool {
+	const maxW1 = uint64(1) << 32
+	return a0.w2 == 0 && a0.w3 == 0 && a0.w1 < maxW1 &&
+		a1.w2 == 0 && a1.w3 == 0 && a1.w1 < maxW1 &&
+		b0.w2 == 0 && b0.w3 == 0 && b0.w1 < maxW1 &&
+		b1.w2 == 0 && b1.w3 == 0 && b1.w1 < maxW1
+}
+
+func s256to128(s signed256) signed128 {
+	return signed128{s.w0, s.w1, s.neg}
+}
+
+// --- Eisenstein arithmetic with signed256 ---
+
+func cubicRoundFloat(x float64) int64 {
+	if x >= 0 {
+		return int64(x + 0.5)
+	}
+	return -int64(-x + 0.5)
+}
+
+// eisQuotient256 computes the nearest Eisenstein quotient of a/b using float64.
+// In ℤ[ω]: q = round(a·conj(b) / N(b)) where conj(b₀+b₁ω) = (b₀-b₁)+(-b₁)ω
+// and N(b₀+b₁ω) = b₀²+b₁²-b₀b₁.
+func eisQuotient256(a0, a1, b0, b1 signed256) (int64, int64) {
+	af0 := s256ToFloat(a0)
+	af1 := s256ToFloat(a1)
+	bf0 := s256ToFloat(b0)
+	bf1 := s256ToFloat(b1)
+
+	nB := bf0*bf0 + bf1*bf1 - bf0*bf1
+	if nB == 0 {
+		return 0, 0
+	}
+
+	// a·conj(b):
+	// Re = a₀b₀ - a₀b₁ + a₁b₁
+	// Im = a₁b₀ - a₀b₁
+	numRe := af0*bf0 - af0*bf1 + af1*bf1
+	numIm := af1*bf0 - af0*bf1
+
+	return cubicRoundFloat(numRe / nB), cubicRoundFloat(numIm / nB)
+}
+
+// eisRem256 computes the Eisenstein remainder a mod b in ℤ[ω].
+func eisRem256(a0, a1, b0, b1 signed256) (signed256, signed256) {
+	qr, qi := eisQuotient256(a0, a1, b0, b1)
+	// q·b = (qr+qi·ω)(b₀+b₁·ω) = (qr·b₀-qi·b₁) + (qr·b₁+qi·b₀-qi·b₁)·ω
+	qb0 := sub256(mulSmall256(b0, qr), mulSmall256(b1, qi))
+	qb1 := sub256(add256(mulSmall256(b1, qr), mulSmall256(b0, qi)), mulSmall256(b1, qi))
+	return sub256(a0, qb0), sub256(a1, qb1)
+}
+
+// divBy1MinusOmega256 divides (e + f·ω) by (1-ω).
+// (e + f·ω)/(1-ω) = ((2e-f)/3) + ((e+f)/3)·ω
+// Valid only when e+f ≡ 0 mod 3.
+func divBy1MinusOmega256(e, f *signed256) {
+	twoEMinusF := sub256(mulSmall256(*e, 2), *f)
+	sum := add256(*e, *f)
+	*e = divExact3_256(twoEMinusF)
+	*f = divExact3_256(sum)
+}
+
+// makePrimaryEis256 finds n (0 ≤ n < 3) such that (e+f·ω)·ω^{-n} is primary.
+// Modifies e, f in place to the primary associate. Returns n.
+func makePrimaryEis256(e, f *signed256) int {
+	r0 := mod3_256(*e)
+	// mod3(e-f) = (mod3(e) + 3 - mod3(f)) % 3
+	r1 := (r0 + 3 - mod3_256(*f)) % 3
+
+	if r0 == 0 {
+		// n=1: multiply by ω² → (f-e) + (-e)·ω
+		newE := sub256(*f, *e)
+		newF := neg256(*e)
+		*e = newE
+		*f = newF
+		return 1
+	}
+	if r1 == 0 {
+		// n=2: multiply by ω → (-f) + (e-f)·ω
+		newE := neg256(*f)
+		newF := sub256(*e, *f)
+		*e = newE
+		*f = newF
+		return 2
+	}
+	return 0
+}
+
+func isRealUnit256(re, im signed256) bool {
+	return im.isZero() && re.w1 == 0 && re.w2 == 0 && re.w3 == 0 && re.w0 <= 1
+}
+
+// --- Two's complement arithmetic modulo 2²⁵⁶ ---
+
+// mulLow4 returns ±q·s mod 2²⁵⁶ in two's complement, where q is a magnitude
+// of sign neg.
+func mulLow4(q *[4]uint64, neg bool, s signed256) [4]uint64 {
+	b := [4]uint64{s.w0, s.w1, s.w2, s.w3}
+	var z [4]uint64
+	for i := range q {
+		var carry uint64
+		for j := 0; i+j < 4; j++ {
+			hi, lo := bits.Mul64(q[i], b[j])
+			var c uint64
+			lo, c = bits.Add64(lo, z[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			z[i+j] = lo
+			carry = hi
+		}
+	}
+	if neg != s.neg {
+		z = neg4(z)
+	}
+	return z
+}
+
+func add4(a, b [4]uint64) [4]uint64 {
+	var c uint64
+	a[0], c = bits.Add64(a[0], b[0], 0)
+	a[1], c = bits.Add64(a[1], b[1], c)
+	a[2], c = bits.Add64(a[2], b[2], c)
+	a[3], _ = bits.Add64(a[3], b[3], c)
+	return a
+}
+
+func sub4(a, b [4]uint64) [4]uint64 {
+	var bw uint64
+	a[0], bw = bits.Sub64(a[0], b[0], 0)
+	a[1], bw = bits.Sub64(a[1], b[1], bw)
+	a[2], bw = bits.Sub64(a[2], b[2], bw)
+	a[3], _ = bits.Sub64(a[3], b[3], bw)
+	return a
+}
+
+func neg4(a [4]uint64) [4]uint64 {
+	return sub4([4]uint64{}, a)
+}
+
+// fromTwos4 converts a 256-bit two's complement integer to a signed256.
+func fromTwos4(a [4]uint64) signed256 {
+	if a[3]>>63 == 0 {
+		return signed256{a[0], a[1], a[2], a[3], false}
+	}
+	a = neg4(a)
+	return signed256{a[0], a[1], a[2], a[3], true}
+}
@@ -0,0 +1,113 @@
+package eisenstein
+
+import (
+	"math/big"
+)
+
+// Mod sets z to the remainder x - ⌊x/y⌉·y of the Euclidean division of x by
+// y, of norm smaller than N(y), and returns z.
+func (z *ComplexNumber) Mod(x, y *ComplexNumber) *ComplexNumber {
+	var q ComplexNumber
+	q.Quo(x, y)
+	q.Mul(&q, y)
+	return z.Sub(x, &q)
+}
+
+// Normalize sets z to the associate of x such that 0 ≤ z[1] < z[0], i.e. such
+// that 0 ≤ arg(z) < π/3, and returns k in [0,5] such that x = ζᵏ·z, where
+// ζ = 1+ω = -ω² is a primitive sixth root of unity. If x = 0, z is set to 0
+// and k = 0.
+func (z *ComplexNumber) Normalize(x *ComplexNumber) int {
+	z.Set(x)
+	if z.A0.Sign() == 0 && z.A1.Sign() == 0 {
+		return 0
+	}
+	k := 0
+	for z.A1.Sign() < 0 || z.A1.Cmp(&z.A0) >= 0 {
+		z.mulByZetaInverse(z)
+		k++
+	}
+	return k
+}
+
+// mulByZetaInverse sets z to ζ⁻¹·x = -ω·x = x[1] + (x[1]-x[0])ω, and returns z.
+func (z *ComplexNumber) mulByZetaInverse(x *ComplexNumber) *ComplexNumber {
+	z.t0.Sub(&x.A1, &x.A0)
+	z.A0.Set(&x.A1)
+	z.A1.Set(&z.t0)
+	return z
+}
+
+// GCD sets z to the greatest common divisor of x and y, normalized as by
+// Normalize, and returns z. GCD(0, 0) = 0.
+func (z *ComplexNumber) GCD(x, y *ComplexNumber) *ComplexNumber {
+	var u, v ComplexNumber
+	a, b := u.Set(x), v.Set(y)
+	for b.A0.Sign() != 0 || b.A1.Sign() != 0 {
+		a.Mod(a, b)
+		a, b = b, a
+	}
+	z.Normalize(a)
+	return z
+}
+
+// ExtendedGCD sets z to GCD(x, y), and s and t to Bézout coefficients such
+// that z = s·x + t·y, and returns z. z, s and t must be distinct.
+func (z *ComplexNumber) ExtendedGCD(s, t, x, y *ComplexNumber) *ComplexNumber {
+	// invariant: r = s·x + t·y for (r0, s0, t0) and (r1, s1, t1)
+	var e [8]ComplexNumber
+	r0, r1, s0, s1, t0, t1, q, u := &e[0], &e[1], &e[2], &e[3], &e[4], &e[5], &e[6], &e[7]
+	r0.Set(x)
+	r1.Set(y)
+	s0.SetOne()
+	s1.SetZero()
+	t0.SetZero()
+	t1.SetOne()
+	for r1.A0.Sign() != 0 || r1.A1.Sign() != 0 {
+		q.Quo(r0, r1)
+		r0.Sub(r0, u.Mul(q, r1))
+		s0.Sub(s0, u.Mul(q, s1))
+		t0.Sub(t0, u.Mul(q, t1))
+		r0, r1 = r1, r0
+		s0, s1 = s1, s0
+		t0, t1 = t1, t0
+	}
+
+	// r0 = ζᵏ·z, so that z = ζ⁻ᵏ·(s0·x + t0·y)
+	k := z.Normalize(r0)
+	for i := 0; i < k; i++ {
+		s0.mulByZetaInverse(s0)
+		t0.mulByZetaInverse(t0)
+	}
+	s.Set(s0)
+	t.Set(t0)
+	return z
+}
+
+// Exp sets z to x^k mod m, and returns z. If m = nil or m = 0, z = x^k. If
+// k ≤ 0, z = 1. The result modulo m is a remainder as returned by Mod, and is
+// not reduced to a canonical representative.
+func (z *ComplexNumber) Exp(x *ComplexNumber, k *big.Int, m *ComplexNumber) *ComplexNumber {
+	mod := m != nil && (m.A0.Sign() != 0 || m.A1.Sign() != 0)
+	var base, res ComplexNumber
+	base.Set(x)
+	res.SetOne()
+	if k.Sign() <= 0 {
+		return z.Set(&res)
+	}
+	if mod {
+		base.Mod(&base, m)
+	}
+
+	// square-and-multiply, from the most significant bit
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		res.Mul(&res, &res)
+		if k.Bit(i) == 1 {
+			res.Mul(&res, &base)
+		}
+		if mod {
+			res.Mod(&res, m)
+		}
+	}
+	return z.Set(&res)
+}
@@ -0,0 +1,170 @@
+package eisenstein
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestGCD(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(boundSize)
+
+	properties.Property("Mod should output a remainder of norm smaller than N(y)", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			if b.A0.Sign() == 0 && b.A1.Sign() == 0 {
+				return true
+			}
+			var q, r, c ComplexNumber
+			q.Quo(a, b)
+			r.Mod(a, b)
+			c.Mul(&q, b).Add(&c, &r)
+			var nr, nb big.Int
+			return c.Equal(a) && r.Norm(&nr).Cmp(b.Norm(&nb)) < 0
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Normalize should output an associate with 0 ≤ z[1] < z[0]", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b, c, zeta ComplexNumber
+			k := b.Normalize(a)
+			if a.A0.Sign() == 0 && a.A1.Sign() == 0 {
+				return k == 0 && b.Equal(a)
+			}
+			// a = ζ^k·b
+			zeta.A0.SetInt64(1)
+			zeta.A1.SetInt64(1)
+			c.Set(&b)
+			for i := 0; i < k; i++ {
+				c.Mul(&c, &zeta)
+			}
+			return k >= 0 && k < 6 && c.Equal(a) &&
+				b.A1.Sign() >= 0 && b.A1.Cmp(&b.A0) < 0 && b.Normalize(&b) == 0
+		},
+		genE,
+	))
+
+	properties.Property("GCD should divide both operands and be a multiple of a common factor", prop.ForAll(
+		func(a, b, c *ComplexNumber) bool {
+			var ac, bc, g, r ComplexNumber
+			ac.Mul(a, c)
+			bc.Mul(b, c)
+			g.GCD(&ac, &bc)
+			if g.A0.Sign() == 0 && g.A1.Sign() == 0 {
+				return ac.Equal(&g) && bc.Equal(&g)
+			}
+			if !isZero(r.Mod(&ac, &g)) || !isZero(r.Mod(&bc, &g)) {
+				return false
+			}
+			return (c.A0.Sign() == 0 && c.A1.Sign() == 0) || isZero(r.Mod(&g, c))
+		},
+		genE,
+		genE,
+		genE,
+	))
+
+	properties.Property("GCD should be symmetric and GCD(x, 0) = Normalize(x)", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var g1, g2, c, zero ComplexNumber
+			g1.GCD(a, b)
+			g2.GCD(b, a)
+			c.Normalize(a)
+			return g1.Equal(&g2) && g1.GCD(a, &zero).Equal(&c) && g2.GCD(&zero, a).Equal(&c)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("ExtendedGCD should output GCD and Bézout coefficients", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var g1, g2, s, t, c, d ComplexNumber
+			g1.GCD(a, b)
+			g2.ExtendedGCD(&s, &t, a, b)
+			c.Mul(&s, a)
+			d.Mul(&t, b)
+			return g1.Equal(&g2) && c.Add(&c, &d).Equal(&g1)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Exp should satisfy x^(j+k) = x^j·x^k", prop.ForAll(
+		func(a *ComplexNumber, j, k uint8) bool {
+			var b, c, d ComplexNumber
+			b.Exp(a, big.NewInt(int64(j)+int64(k)), nil)
+			c.Exp(a, big.NewInt(int64(j)), nil)
+			d.Exp(a, big.NewInt(int64(k)), nil)
+			return b.Equal(c.Mul(&c, &d))
+		},
+		GenSignedComplexNumber(16),
+		genUint8(),
+		genUint8(),
+	))
+
+	properties.Property("Exp mod m should be congruent to Exp", prop.ForAll(
+		func(a, m *ComplexNumber, k uint8) bool {
+			if m.A0.Sign() == 0 && m.A1.Sign() == 0 {
+				return true
+			}
+			var b, c, r ComplexNumber
+			b.Exp(a, big.NewInt(int64(k)), m)
+			c.Exp(a, big.NewInt(int64(k)), nil)
+			var nb, nm big.Int
+			return isZero(r.Sub(&c, &b).Mod(&r, m)) && b.Norm(&nb).Cmp(m.Norm(&nm)) < 0
+		},
+		GenSignedComplexNumber(16),
+		GenSignedComplexNumber(16),
+		genUint8(),
+	))
+
+	properties.Property("Exp by (N(β)-1)/3 should output ω^[α/β]₃ mod β", prop.ForAll(
+		func(c *CubicSymbolContext, a *ComplexNumber) bool {
+			k, err := CubicResidueSymbol(a, c.Prime())
+			if err != nil {
+				return true
+			}
+			var b, w, r ComplexNumber
+			b.Exp(a, &c.exp, c.Prime())
+			w.Exp(omega(), big.NewInt(int64(k)), nil)
+			return isZero(r.Sub(&b, &w).Mod(&r, c.Prime()))
+		},
+		GenCubicSymbolContext(),
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// x^0 = 1 and x^k = 1 for k < 0
+	var a, b, one ComplexNumber
+	one.SetOne()
+	for _, k := range []int64{0, -1} {
+		if !b.Exp(&a, big.NewInt(k), nil).Equal(&one) {
+			t.Fatalf("expected 0^%d = 1, got %v", k, &b)
+		}
+	}
+}
+
+// isZero returns true if z = 0.
+func isZero(z *ComplexNumber) bool {
+	return z.A0.Sign() == 0 && z.A1.Sign() == 0
+}
+
+// genUint8 generates a random uint8
+func genUint8() gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		return gopter.NewGenResult(uint8(genParams.Rng.Intn(256)), gopter.NoShrinker)
+	}
+}
@@ -0,0 +1,266 @@
+package eisenstein
+
+import (
+	"math/big"
+)
+
+// An Int256 represents an Eisenstein integer A0 + A1·ω whose coordinates are
+// signed 256-bit integers, in two's complement with little-endian 64-bit
+// words.
+//
+// Unlike ComplexNumber, Int256 is a value type: it doesn't allocate, can be
+// copied, compared with == and shared by concurrent readers. The operations
+// return false when the result overflows; ComplexNumber handles the cases
+// that don't fit.
+type Int256 struct {
+	A0, A1 [4]uint64
+}
+
+// An Int512 is like Int256 with signed 512-bit coordinates.
+type Int512 struct {
+	A0, A1 [8]uint64
+}
+
+// NewInt256 returns a0 + a1·ω.
+func NewInt256(a0, a1 int64) Int256 {
+	var z Int256
+	signExtendW(z.A0[:], []uint64{uint64(a0)})
+	signExtendW(z.A1[:], []uint64{uint64(a1)})
+	return z
+}
+
+// String implements Stringer interface for fancy printing
+func (x Int256) String() string {
+	var z ComplexNumber
+	return z.SetInt256(x).String()
+}
+
+// IsZero returns true if x = 0.
+func (x Int256) IsZero() bool {
+	return isZeroW(x.A0[:]) && isZeroW(x.A1[:])
+}
+
+// Int512 returns x as an Int512.
+func (x Int256) Int512() Int512 {
+	var z Int512
+	signExtendW(z.A0[:], x.A0[:])
+	signExtendW(z.A1[:], x.A1[:])
+	return z
+}
+
+// Neg returns -x, and false if it overflows.
+func (x Int256) Neg() (Int256, bool) {
+	var z Int256
+	ok0 := negW(z.A0[:], x.A0[:])
+	ok1 := negW(z.A1[:], x.A1[:])
+	return z, ok0 && ok1
+}
+
+// Conjugate returns the conjugate (x₀ - x₁) - x₁ω of x, and false if it
+// overflows.
+func (x Int256) Conjugate() (Int256, bool) {
+	var z Int256
+	ok0 := subW(z.A0[:], x.A0[:], x.A1[:])
+	ok1 := negW(z.A1[:], x.A1[:])
+	return z, ok0 && ok1
+}
+
+// Add returns x + y, and false if it overflows.
+func (x Int256) Add(y Int256) (Int256, bool) {
+	var z Int256
+	ok0 := addW(z.A0[:], x.A0[:], y.A0[:])
+	ok1 := addW(z.A1[:], x.A1[:], y.A1[:])
+	return z, ok0 && ok1
+}
+
+// Sub returns x - y, and false if it overflows.
+func (x Int256) Sub(y Int256) (Int256, bool) {
+	var z Int256
+	ok0 := subW(z.A0[:], x.A0[:], y.A0[:])
+	ok1 := subW(z.A1[:], x.A1[:], y.A1[:])
+	return z, ok0 && ok1
+}
+
+// Mul returns x·y, and false if it overflows.
+func (x Int256) Mul(y Int256) (Int256, bool) {
+	var z Int256
+	var z0, z1 [2*4 + 1]uint64
+	eisMulW(z0[:], z1[:], x.A0[:], x.A1[:], y.A0[:], y.A1[:])
+	copy(z.A0[:], z0[:])
+	copy(z.A1[:], z1[:])
+	return z, fitsW(z0[:], 4) && fitsW(z1[:], 4)
+}
+
+// Norm sets norm to the norm x₀² - x₀x₁ + x₁² of x, and returns norm.
+func (x Int256) Norm(norm *big.Int) *big.Int {
+	var n [2*4 + 1]uint64
+	eisNormW(n[:], x.A0[:], x.A1[:])
+	return wordsToBigW(norm, n[:])
+}
+
+// Quo returns the Euclidean quotient ⌊x/y⌉ of x by y, as ComplexNumber.Quo,
+// and false if it overflows. It panics if y = 0.
+func (x Int256) Quo(y Int256) (Int256, bool) {
+	var z Int256
+	ok := eisQuoW(z.A0[:], z.A1[:], x.A0[:], x.A1[:], y.A0[:], y.A1[:])
+	return z, ok
+}
+
+// Rem returns the remainder x - ⌊x/y⌉·y, of norm smaller than N(y), and false
+// if it overflows. It panics if y = 0.
+func (x Int256) Rem(y Int256) (Int256, bool) {
+	var z Int256
+	ok := eisRemW(z.A0[:], z.A1[:], x.A0[:], x.A1[:], y.A0[:], y.A1[:])
+	return z, ok
+}
+
+// GCD returns a greatest common divisor of x and y, defined up to a unit, and
+// false if an intermediate remainder overflows. GCD(0, 0) = 0.
+func (x Int256) GCD(y Int256) (Int256, bool) {
+	for !y.IsZero() {
+		r, ok := x.Rem(y)
+		if !ok {
+			return Int256{}, false
+		}
+		x, y = y, r
+	}
+	return x, true
+}
+
+// NewInt512 returns a0 + a1·ω.
+func NewInt512(a0, a1 int64) Int512 {
+	var z Int512
+	signExtendW(z.A0[:], []uint64{uint64(a0)})
+	signExtendW(z.A1[:], []uint64{uint64(a1)})
+	return z
+}
+
+// String implements Stringer interface for fancy printing
+func (x Int512) String() string {
+	var z ComplexNumber
+	return z.SetInt512(x).String()
+}
+
+// IsZero returns true if x = 0.
+func (x Int512) IsZero() bool {
+	return isZeroW(x.A0[:]) && isZeroW(x.A1[:])
+}
+
+// Int256 returns x as an Int256, and false if it doesn't fit.
+func (x Int512) Int256() (Int256, bool) {
+	var z Int256
+	copy(z.A0[:], x.A0[:])
+	copy(z.A1[:], x.A1[:])
+	return z, fitsW(x.A0[:], 4) && fitsW(x.A1[:], 4)
+}
+
+// Neg returns -x, and false if it overflows.
+func (x Int512) Neg() (Int512, bool) {
+	var z Int512
+	ok0 := negW(z.A0[:], x.A0[:])
+	ok1 := negW(z.A1[:], x.A1[:])
+	return z, ok0 && ok1
+}
+
+// Conjugate returns the conjugate (x₀ - x₁) - x₁ω of x, and false if it
+// overflows.
+func (x Int512) Conjugate() (Int512, bool) {
+	var z Int512
+	ok0 := subW(z.A0[:], x.A0[:], x.A1[:])
+	ok1 := negW(z.A1[:], x.A1[:])
+	return z, ok0 && ok1
+}
+
+// Add returns x + y, and false if it overflows.
+func (x Int512) Add(y Int512) (Int512, bool) {
+	var z Int512
+	ok0 := addW(z.A0[:], x.A0[:], y.A0[:])
+	ok1 := addW(z.A1[:], x.A1[:], y.A1[:])
+	return z, ok0 && ok1
+}
+
+// Sub returns x - y, and false if it overflows.
+func (x Int512) Sub(y Int512) (Int512, bool) {
+	var z Int512
+	ok0 := subW(z.A0[:], x.A0[:], y.A0[:])
+	ok1 := subW(z.A1[:], x.A1[:], y.A1[:])
+	return z, ok0 && ok1
+}
+
+// Mul returns x·y, and false if it overflows.
+func (x Int512) Mul(y Int512) (Int512, bool) {
+	var z Int512
+	var z0, z1 [2*8 + 1]uint64
+	eisMulW(z0[:], z1[:], x.A0[:], x.A1[:], y.A0[:], y.A1[:])
+	copy(z.A0[:], z0[:])
+	copy(z.A1[:], z1[:])
+	return z, fitsW(z0[:], 8) && fitsW(z1[:], 8)
+}
+
+// Norm sets norm to the norm x₀² - x₀x₁ + x₁² of x, and returns norm.
+func (x Int512) Norm(norm *big.Int) *big.Int {
+	var n [2*8 + 1]uint64
+	eisNormW(n[:], x.A0[:], x.A1[:])
+	return wordsToBigW(norm, n[:])
+}
+
+// Quo returns the Euclidean quotient ⌊x/y⌉ of x by y, as ComplexNumber.Quo,
+// and false if it overflows. It panics if y = 0.
+func (x Int512) Quo(y Int512) (Int512, bool) {
+	var z Int512
+	ok := eisQuoW(z.A0[:], z.A1[:], x.A0[:], x.A1[:], y.A0[:], y.A1[:])
+	return z, ok
+}
+
+// Rem returns the remainder x - ⌊x/y⌉·y, of norm smaller than N(y), and false
+// if it overflows. It panics if y = 0.
+func (x Int512) Rem(y Int512) (Int512, bool) {
+	var z Int512
+	ok := eisRemW(z.A0[:], z.A1[:], x.A0[:], x.A1[:], y.A0[:], y.A1[:])
+	return z, ok
+}
+
+// GCD returns a greatest common divisor of x and y, defined up to a unit, and
+// false if an intermediate remainder overflows. GCD(0, 0) = 0.
+func (x Int512) GCD(y Int512) (Int512, bool) {
+	for !y.IsZero() {
+		r, ok := x.Rem(y)
+		if !ok {
+			return Int512{}, false
+		}
+		x, y = y, r
+	}
+	return x, true
+}
+
+// SetInt256 sets z to x, and returns z.
+func (z *ComplexNumber) SetInt256(x Int256) *ComplexNumber {
+	wordsToBigW(&z.A0, x.A0[:])
+	wordsToBigW(&z.A1, x.A1[:])
+	return z
+}
+
+// Int256 returns z as an Int256, and false if it doesn't fit.
+func (z *ComplexNumber) Int256() (Int256, bool) {
+	var x Int256
+	if !bigToWordsW(x.A0[:], &z.A0) || !bigToWordsW(x.A1[:], &z.A1) {
+		return Int256{}, false
+	}
+	return x, true
+}
+
+// SetInt512 sets z to x, and returns z.
+func (z *ComplexNumber) SetInt512(x Int512) *ComplexNumber {
+	wordsToBigW(&z.A0, x.A0[:])
+	wordsToBigW(&z.A1, x.A1[:])
+	return z
+}
+
+// Int512 returns z as an Int512, and false if it doesn't fit.
+func (z *ComplexNumber) Int512() (Int512, bool) {
+	var x Int512
+	if !bigToWordsW(x.A0[:], &z.A0) || !bigToWordsW(x.A1[:], &z.A1) {
+		return Int512{}, false
+	}
+	return x, true
+}
@@ -0,0 +1,332 @@
+package eisenstein
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// Arithmetic on signed integers of n 64-bit words, in two's complement with
+// little-endian words. The Eisenstein operations of Int256 (n=4) and Int512
+// (n=8) compute exactly on 2n+1 words, which holds the sum of three products
+// of n-word integers, and then check that the result fits in n words.
+
+// wide is the number of words of the intermediate results of Int512.
+const wide = 2*8 + 1
+
+// isNegW returns true if x < 0.
+func isNegW(x []uint64) bool {
+	return x[len(x)-1]>>63 != 0
+}
+
+// isZeroW returns true if x = 0.
+func isZeroW(x []uint64) bool {
+	for _, w := range x {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// signExtendW sets z to x, with len(z) ≥ len(x).
+func signExtendW(z, x []uint64) {
+	var s uint64
+	if isNegW(x) {
+		s = ^uint64(0)
+	}
+	copy(z, x)
+	for i := len(x); i < len(z); i++ {
+		z[i] = s
+	}
+}
+
+// fitsW returns true if x fits in n signed words, i.e. if the words of x
+// above n are the sign extension of x[n-1].
+func fitsW(x []uint64, n int) bool {
+	var s uint64
+	if x[n-1]>>63 != 0 {
+		s = ^uint64(0)
+	}
+	for _, w := range x[n:] {
+		if w != s {
+			return false
+		}
+	}
+	return true
+}
+
+// addW sets z = x + y, and returns false if it overflows.
+func addW(z, x, y []uint64) bool {
+	sx, sy := isNegW(x), isNegW(y)
+	var c uint64
+	for i := range z {
+		z[i], c = bits.Add64(x[i], y[i], c)
+	}
+	return sx != sy || isNegW(z) == sx
+}
+
+// subW sets z = x - y, and returns false if it overflows.
+func subW(z, x, y []uint64) bool {
+	sx, sy := isNegW(x), isNegW(y)
+	var b uint64
+	for i := range z {
+		z[i], b = bits.Sub64(x[i], y[i], b)
+	}
+	return sx == sy || isNegW(z) == sx
+}
+
+// negW sets z = -x, and returns false if it overflows, i.e. if x is the
+// smallest integer.
+func negW(z, x []uint64) bool {
+	sx := isNegW(x)
+	var b uint64
+	for i := range z {
+		z[i], b = bits.Sub64(0, x[i], b)
+	}
+	return !sx || !isNegW(z)
+}
+
+// absW sets z = |x| as an unsigned integer, and returns true if x < 0.
+func absW(z, x []uint64) bool {
+	if isNegW(x) {
+		negW(z, x)
+		return true
+	}
+	copy(z, x)
+	return false
+}
+
+// mulW sets z = x·y, with len(z) ≥ len(x) + len(y) so that it doesn't
+// overflow. z must not alias x or y.
+func mulW(z, x, y []uint64) {
+	var ax, ay [wide]uint64
+	nx := absW(ax[:len(x)], x)
+	ny := absW(ay[:len(y)], y)
+
+	for i := range z {
+		z[i] = 0
+	}
+	for i := range x {
+		var carry uint64
+		for j := range y {
+			hi, lo := bits.Mul64(ax[i], ay[j])
+			var c uint64
+			lo, c = bits.Add64(lo, z[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			z[i+j] = lo
+			carry = hi
+		}
+		z[i+len(y)] = carry
+	}
+	if nx != ny {
+		negW(z, z)
+	}
+}
+
+// roundQuoW sets q = round(x/d) for d > 0, rounding half away from zero as
+// ComplexNumber.Quo does. q must not alias x or d.
+func roundQuoW(q, x, d []uint64) {
+	var ax, r [wide]uint64
+	neg := absW(ax[:len(x)], x)
+	rr := r[:len(d)]
+
+	// schoolbook binary long division: q = ⌊|x|/d⌋ and r = |x| mod d. The
+	// quotient has at most k+1 bits, and r starts as the top bits of |x|.
+	for i := range q {
+		q[i] = 0
+	}
+	k := bitLenW(ax[:len(x)]) - bitLenW(d)
+	shrW(rr, ax[:len(x)], k+1)
+	for i := k; i >= 0; i-- {
+		shl1W(rr)
+		rr[0] |= ax[i/64] >> (i % 64) & 1
+		if cmpW(rr, d) >= 0 {
+			subW(rr, rr, d)
+			q[i/64] |= 1 << (i % 64)
+		}
+	}
+
+	// 2r ≥ d: round up
+	shl1W(rr)
+	if cmpW(rr, d) >= 0 {
+		var c uint64 = 1
+		for i := range q {
+			q[i], c = bits.Add64(q[i], 0, c)
+		}
+	}
+	if neg {
+		negW(q, q)
+	}
+}
+
+// bitLenW returns the bit length of the unsigned integer x.
+func bitLenW(x []uint64) int {
+	for i := len(x) - 1; i >= 0; i-- {
+		if x[i] != 0 {
+			return i*64 + bits.Len64(x[i])
+		}
+	}
+	return 0
+}
+
+// shrW sets z = ⌊x/2ˢ⌋ for the unsigned integer x, with len(z) ≤ len(x).
+func shrW(z, x []uint64, s int) {
+	if s < 0 {
+		s = 0
+	}
+	w, b := s/64, uint(s%64)
+	for i := range z {
+		var lo, hi uint64
+		if i+w < len(x) {
+			lo = x[i+w]
+		}
+		if i+w+1 < len(x) {
+			hi = x[i+w+1]
+		}
+		z[i] = lo>>b | hi<<(64-b)
+	}
+}
+
+// shl1W sets x = 2x.
+func shl1W(x []uint64) {
+	for i := len(x) - 1; i > 0; i-- {
+		x[i] = x[i]<<1 | x[i-1]>>63
+	}
+	x[0] <<= 1
+}
+
+// cmpW compares the unsigned integers x and y of the same length.
+func cmpW(x, y []uint64) int {
+	for i := len(x) - 1; i >= 0; i-- {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// wordsToBigW sets z to the signed integer x, and returns z.
+func wordsToBigW(z *big.Int, x []uint64) *big.Int {
+	var ax [wide]uint64
+	neg := absW(ax[:len(x)], x)
+	var buf [wide * 8]byte
+	b := buf[:len(x)*8]
+	for i := range x {
+		binary.BigEndian.PutUint64(b[len(b)-8*(i+1):], ax[i])
+	}
+	z.SetBytes(b)
+	if neg {
+		z.Neg(z)
+	}
+	return z
+}
+
+// bigToWordsW sets z to x, and returns false if x doesn't fit in len(z)
+// signed words.
+func bigToWordsW(z []uint64, x *big.Int) bool {
+	if x.BitLen() >= 64*len(z) {
+		// only the smallest integer -2^(64n-1) fits
+		if x.Sign() >= 0 || x.TrailingZeroBits() != uint(64*len(z)-1) || x.BitLen() != 64*len(z) {
+			return false
+		}
+	}
+	var buf [wide * 8]byte
+	b := buf[:len(z)*8]
+	x.FillBytes(b)
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(b[len(b)-8*(i+1):])
+	}
+	if x.Sign() < 0 {
+		negW(z, z)
+	}
+	return true
+}
+
+// --- Eisenstein arithmetic on n-word coordinates ---
+
+// eisMulW sets (z0, z1) to the exact product of x and y, with z0 and z1 of
+// length 2n+1, and x and y of length n. z must not alias x or y.
+//
+//	(x₀ + x₁ω)(y₀ + y₁ω) = (x₀y₀ - x₁y₁) + (x₀y₁ + x₁y₀ - x₁y₁)ω
+func eisMulW(z0, z1, x0, x1, y0, y1 []uint64) {
+	w := len(z0)
+	var t0, t1, t2 [wide]uint64
+	mulW(t0[:w], x0, y0)
+	mulW(t1[:w], x1, y1)
+	mulW(t2[:w], x0, y1)
+	mulW(z1, x1, y0)
+
+	subW(z0, t0[:w], t1[:w])
+	addW(z1, z1, t2[:w])
+	subW(z1, z1, t1[:w])
+}
+
+// eisMulByConjugateW sets (z0, z1) to the exact product of x and the
+// conjugate of y, as eisMulW.
+//
+//	x·ȳ = (x₀y₀ + x₁y₁ - x₀y₁) + (x₁y₀ - x₀y₁)ω
+func eisMulByConjugateW(z0, z1, x0, x1, y0, y1 []uint64) {
+	w := len(z0)
+	var t0, t1, t2 [wide]uint64
+	mulW(t0[:w], x0, y0)
+	mulW(t1[:w], x1, y1)
+	mulW(t2[:w], x0, y1)
+	mulW(z1, x1, y0)
+
+	addW(z0, t0[:w], t1[:w])
+	subW(z0, z0, t2[:w])
+	subW(z1, z1, t2[:w])
+}
+
+// eisNormW sets z to the exact norm x₀² - x₀x₁ + x₁² of x, with z of length
+// 2n+1 and x of length n.
+func eisNormW(z, x0, x1 []uint64) {
+	var t [wide]uint64
+	eisMulByConjugateW(z, t[:len(z)], x0, x1, x0, x1)
+}
+
+// eisQuoW sets z to ⌊x/y⌉ = round(x·ȳ/N(y)), as ComplexNumber.Quo does, and
+// returns false if it doesn't fit in n words. It panics if y = 0.
+func eisQuoW(z0, z1, x0, x1, y0, y1 []uint64) bool {
+	w := 2*len(x0) + 1
+	var num0, num1, norm, q0, q1 [wide]uint64
+	eisNormW(norm[:w], y0, y1)
+	if isZeroW(norm[:w]) {
+		panic("eisenstein: division by zero")
+	}
+	eisMulByConjugateW(num0[:w], num1[:w], x0, x1, y0, y1)
+	roundQuoW(q0[:w], num0[:w], norm[:w])
+	roundQuoW(q1[:w], num1[:w], norm[:w])
+
+	n := len(z0)
+	copy(z0, q0[:n])
+	copy(z1, q1[:n])
+	return fitsW(q0[:w], n) && fitsW(q1[:w], n)
+}
+
+// eisRemW sets z to x - ⌊x/y⌉·y, and returns false if ⌊x/y⌉ or z doesn't fit
+// in n words. It panics if y = 0.
+func eisRemW(z0, z1, x0, x1, y0, y1 []uint64) bool {
+	n := len(x0)
+	w := 2*n + 1
+	var q0, q1 [wide / 2]uint64
+	if !eisQuoW(q0[:n], q1[:n], x0, x1, y0, y1) {
+		return false
+	}
+	var p0, p1, r0, r1 [wide]uint64
+	eisMulW(p0[:w], p1[:w], q0[:n], q1[:n], y0, y1)
+	signExtendW(r0[:w], x0)
+	signExtendW(r1[:w], x1)
+	subW(r0[:w], r0[:w], p0[:w])
+	subW(r1[:w], r1[:w], p1[:w])
+
+	copy(z0, r0[:n])
+	copy(z1, r1[:n])
+	return fitsW(r0[:w], n) && fitsW(r1[:w], n)
+}
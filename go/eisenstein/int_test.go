@@ -0,0 +1,228 @@
+package eisenstein
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestInt256(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(255)
+
+	properties.Property("Int256 should round-trip through ComplexNumber", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			x, ok := a.Int256()
+			return ok && b.SetInt256(x).Equal(a) && x.String() == a.String()
+		},
+		genE,
+	))
+
+	properties.Property("Int256 arithmetic should output same result as ComplexNumber", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			x, _ := a.Int256()
+			y, _ := b.Int256()
+			var c ComplexNumber
+			return fitsInt256(c.Add(a, b))(x.Add(y)) &&
+				fitsInt256(c.Sub(a, b))(x.Sub(y)) &&
+				fitsInt256(c.Mul(a, b))(x.Mul(y)) &&
+				fitsInt256(c.Neg(a))(x.Neg()) &&
+				fitsInt256(c.Conjugate(a))(x.Conjugate())
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Int256 norm should output same result as ComplexNumber", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			x, _ := a.Int256()
+			var n1, n2 big.Int
+			return x.Norm(&n1).Cmp(a.Norm(&n2)) == 0
+		},
+		genE,
+	))
+
+	properties.Property("Int256 Quo and Rem should output same result as ComplexNumber", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			if b.A0.Sign() == 0 && b.A1.Sign() == 0 {
+				return true
+			}
+			x, _ := a.Int256()
+			y, _ := b.Int256()
+			var q, r ComplexNumber
+			q.Quo(a, b)
+			r.Mul(&q, b).Sub(a, &r)
+			var nr, nb big.Int
+			return fitsInt256(&q)(x.Quo(y)) && fitsInt256(&r)(x.Rem(y)) &&
+				r.Norm(&nr).Cmp(b.Norm(&nb)) < 0
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Int256 GCD should divide both operands and be a multiple of a common factor", prop.ForAll(
+		func(a, b, c *ComplexNumber) bool {
+			// a·c and b·c, with 80-bit coordinates
+			var ac, bc ComplexNumber
+			ac.Mul(a, c)
+			bc.Mul(b, c)
+			x, _ := ac.Int256()
+			y, _ := bc.Int256()
+			z, _ := c.Int256()
+			g, ok := x.GCD(y)
+			if !ok {
+				return false
+			}
+			if g.IsZero() {
+				return x.IsZero() && y.IsZero()
+			}
+			r0, ok0 := x.Rem(g)
+			r1, ok1 := y.Rem(g)
+			r2, ok2 := g.Rem(z)
+			return ok0 && ok1 && ok2 && r0.IsZero() && r1.IsZero() && (z.IsZero() || r2.IsZero())
+		},
+		GenSignedComplexNumber(40),
+		GenSignedComplexNumber(40),
+		GenSignedComplexNumber(40),
+	))
+
+	properties.Property("Int512 should round-trip through Int256 and ComplexNumber", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			x, _ := a.Int256()
+			y, ok := x.Int512().Int256()
+			u, ok1 := a.Int512()
+			return ok && ok1 && x == y && u == x.Int512() && b.SetInt512(u).Equal(a) && u.String() == a.String()
+		},
+		genE,
+	))
+
+	properties.Property("Int512 arithmetic should output same result as ComplexNumber", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			x, _ := a.Int512()
+			y, _ := b.Int512()
+			var c, r ComplexNumber
+			if !fitsInt512(c.Add(a, b))(x.Add(y)) ||
+				!fitsInt512(c.Sub(a, b))(x.Sub(y)) ||
+				!fitsInt512(c.Mul(a, b))(x.Mul(y)) ||
+				!fitsInt512(c.Neg(a))(x.Neg()) ||
+				!fitsInt512(c.Conjugate(a))(x.Conjugate()) {
+				return false
+			}
+			var n1, n2 big.Int
+			if x.Norm(&n1).Cmp(a.Norm(&n2)) != 0 {
+				return false
+			}
+			if b.A0.Sign() == 0 && b.A1.Sign() == 0 {
+				return true
+			}
+			c.Quo(a, b)
+			r.Mul(&c, b).Sub(a, &r)
+			g, ok := x.GCD(y)
+			return fitsInt512(&c)(x.Quo(y)) && fitsInt512(&r)(x.Rem(y)) && ok && !g.IsZero()
+		},
+		GenSignedComplexNumber(511),
+		GenSignedComplexNumber(511),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// overflows
+	var a ComplexNumber
+	a.A0.Lsh(big.NewInt(1), 255)
+	if _, ok := a.Int256(); ok {
+		t.Fatal("2²⁵⁵ should not fit in an Int256")
+	}
+	a.A0.Neg(&a.A0)
+	minInt, ok := a.Int256()
+	if !ok {
+		t.Fatal("-2²⁵⁵ should fit in an Int256")
+	}
+	if _, ok := minInt.Neg(); ok {
+		t.Fatal("-(-2²⁵⁵) should overflow")
+	}
+	if _, ok := minInt.Add(NewInt256(-1, 0)); ok {
+		t.Fatal("-2²⁵⁵ - 1 should overflow")
+	}
+	if _, ok := minInt.Sub(NewInt256(0, 1)); !ok {
+		t.Fatal("-2²⁵⁵ - ω should not overflow")
+	}
+	if _, ok := minInt.Quo(NewInt256(-1, 0)); ok {
+		t.Fatal("-2²⁵⁵ / -1 should overflow")
+	}
+	if x := NewInt256(-3, 5); x.String() != "-3+(5*ω)" {
+		t.Fatalf("unexpected %s", x)
+	}
+}
+
+// fitsInt256 returns a check that the Int256 result x of an operation is ok
+// and equal to the ComplexNumber result a iff a fits in an Int256.
+func fitsInt256(a *ComplexNumber) func(x Int256, ok bool) bool {
+	y, fits := a.Int256()
+	return func(x Int256, ok bool) bool {
+		return ok == fits && (!ok || x == y)
+	}
+}
+
+// fitsInt512 is like fitsInt256 for Int512.
+func fitsInt512(a *ComplexNumber) func(x Int512, ok bool) bool {
+	y, fits := a.Int512()
+	return func(x Int512, ok bool) bool {
+		return ok == fits && (!ok || x == y)
+	}
+}
+
+// GenSignedComplexNumber generates a random Eisenstein integer with signed
+// coordinates of up to boundSize bits
+func GenSignedComplexNumber(boundSize int) gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		var r ComplexNumber
+		for _, c := range []*big.Int{&r.A0, &r.A1} {
+			var bound big.Int
+			bound.Lsh(big.NewInt(1), uint(1+genParams.Rng.Intn(boundSize)))
+			x, _ := rand.Int(genParams.Rng, &bound)
+			c.Set(x)
+			if genParams.Rng.Intn(2) == 0 {
+				c.Neg(c)
+			}
+		}
+		return gopter.NewGenResult(&r, gopter.NoShrinker)
+	}
+}
+
+// bench
+func BenchmarkInt256(b *testing.B) {
+	var a, c ComplexNumber
+	a.A0.SetString("-1155048275357884106335086113613464118783412807316232579754", 10)
+	a.A1.SetString("1155048275357884106335086113613464118768280431093290937003", 10)
+	c.A0.SetString("2929494998551518193999723405412053246602204569353345363675", 10)
+	c.A1.SetString("-794262224468384146017685416659704346369932930853282892458", 10)
+	x, _ := a.Int256()
+	y, _ := c.Int256()
+
+	b.Run("Mul", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			x.Mul(y)
+		}
+	})
+	b.Run("Quo", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			y.Quo(x)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package eisenstein
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrNotPrime means that a rational integer is not a prime.
+var ErrNotPrime = errors.New("not a prime")
+
+// PrimeAbove returns the Eisenstein prime β = a + b·ω of norm p which is
+// primary, i.e. a ≡ 2 mod 3 and b ≡ 0 mod 3, and such that b > 0. The other
+// primary prime of norm p is its conjugate.
+//
+// β is the GCD of p and ζ - ω in ℤ[ω], where ζ is a primitive cube root of
+// unity modulo p, so that ω ≡ ζ or ζ² mod β.
+//
+// It returns ErrInvalidPrime if p is not a prime p ≡ 1 mod 3.
+func PrimeAbove(p *big.Int) (*ComplexNumber, error) {
+	var t big.Int
+	if p.Sign() <= 0 || t.Mod(p, big.NewInt(3)).Uint64() != 1 || !p.ProbablyPrime(20) {
+		return nil, ErrInvalidPrime
+	}
+
+	// ζ = g^((p-1)/3) ≠ 1 for the first g that is not a cubic residue
+	var exp, zeta big.Int
+	exp.Sub(p, big.NewInt(1)).Quo(&exp, big.NewInt(3))
+	for g := int64(2); ; g++ {
+		zeta.Exp(big.NewInt(g), &exp, p)
+		if zeta.Cmp(big.NewInt(1)) != 0 {
+			break
+		}
+	}
+
+	// Euclid's algorithm on p and ζ - ω: the remainders have decreasing norms
+	var x, y, q ComplexNumber
+	x.A0.Set(p)
+	y.A0.Set(&zeta)
+	y.A1.SetInt64(-1)
+	r0, r1 := &x, &y
+	for r1.A0.Sign() != 0 || r1.A1.Sign() != 0 {
+		q.Quo(r0, r1)
+		q.Mul(&q, r1)
+		r0.Sub(r0, &q)
+		r0, r1 = r1, r0
+	}
+
+	beta := new(ComplexNumber)
+	beta.Set(r0)
+	if beta.Norm(&t).Cmp(p) != 0 {
+		return nil, ErrInvalidPrime
+	}
+	if _, err := beta.MakePrimary(beta); err != nil {
+		return nil, err
+	}
+	if beta.A1.Sign() < 0 {
+		beta.Conjugate(beta)
+	}
+	return beta, nil
+}
+
+// FactorPrime returns the factorization of the rational prime p into
+// Eisenstein primes, up to a unit:
+//   - p = 3 ramifies: 3 = -ω²·(1-ω)², and it returns [1-ω, 1-ω]
+//   - p ≡ 2 mod 3 is inert, and it returns [p]
+//   - p ≡ 1 mod 3 splits: p = β·β̄, and it returns [β, β̄] for β = PrimeAbove(p)
+//
+// The primes above p ≠ 3 are primary. It returns ErrNotPrime if p is not a
+// prime.
+func FactorPrime(p *big.Int) ([]*ComplexNumber, error) {
+	if p.Sign() <= 0 || !p.ProbablyPrime(20) {
+		return nil, ErrNotPrime
+	}
+
+	var t big.Int
+	switch t.Mod(p, three).Uint64() {
+	case 0:
+		var pi [2]ComplexNumber
+		for i := range pi {
+			pi[i].A0.SetInt64(1)
+			pi[i].A1.SetInt64(-1)
+		}
+		return []*ComplexNumber{&pi[0], &pi[1]}, nil
+	case 2:
+		pi := new(ComplexNumber)
+		pi.A0.Set(p)
+		return []*ComplexNumber{pi}, nil
+	default:
+		beta, err := PrimeAbove(p)
+		if err != nil {
+			return nil, err
+		}
+		return []*ComplexNumber{beta, new(ComplexNumber).Conjugate(beta)}, nil
+	}
+}
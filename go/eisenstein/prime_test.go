@@ -0,0 +1,125 @@
+package eisenstein
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestPrimeAbove(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("PrimeAbove should output a primary prime of norm p", prop.ForAll(
+		func(p *big.Int) bool {
+			beta, err := PrimeAbove(p)
+			if err != nil {
+				return false
+			}
+			var norm, r0, r1 big.Int
+			return beta.Norm(&norm).Cmp(p) == 0 && beta.A1.Sign() > 0 &&
+				r0.Mod(&beta.A0, big.NewInt(3)).Uint64() == 2 && r1.Mod(&beta.A1, big.NewInt(3)).Sign() == 0
+		},
+		GenPrime1Mod3(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// the hard-coded primes of the curves
+	for _, beta := range curveBetas {
+		var expected ComplexNumber
+		expected.A0.SetString(beta.a, 10)
+		expected.A1.SetString(beta.b, 10)
+		var p big.Int
+		expected.Norm(&p)
+		got, err := PrimeAbove(&p)
+		if err != nil || !got.Equal(&expected) {
+			t.Fatalf("%s: expected %v, got %v, %v", beta.name, &expected, got, err)
+		}
+	}
+
+	// small primes
+	for _, p := range []int64{7, 13, 19, 31, 37, 43} {
+		beta, err := PrimeAbove(big.NewInt(p))
+		if err != nil || beta.Norm(new(big.Int)).Int64() != p {
+			t.Fatalf("%d: unexpected prime %v, %v", p, beta, err)
+		}
+	}
+	for _, p := range []int64{-7, 0, 1, 2, 3, 5, 25, 49, 91} {
+		if _, err := PrimeAbove(big.NewInt(p)); !errors.Is(err, ErrInvalidPrime) {
+			t.Fatalf("%d: expected ErrInvalidPrime, got %v", p, err)
+		}
+	}
+}
+
+// GenPrime1Mod3 generates a random prime p ≡ 1 mod 3 of up to 384 bits
+func GenPrime1Mod3() gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		size := 3 + genParams.Rng.Intn(382)
+		for {
+			p, _ := rand.Prime(genParams.Rng, size)
+			if new(big.Int).Mod(p, big.NewInt(3)).Uint64() == 1 {
+				return gopter.NewGenResult(p, gopter.NoShrinker)
+			}
+		}
+	}
+}
+
+func TestFactorPrime(t *testing.T) {
+	t.Parallel()
+
+	for p := int64(2); p < 200; p++ {
+		pb := big.NewInt(p)
+		factors, err := FactorPrime(pb)
+		if !pb.ProbablyPrime(20) {
+			if !errors.Is(err, ErrNotPrime) {
+				t.Fatalf("%d: expected ErrNotPrime, got %v", p, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d: unexpected error %v", p, err)
+		}
+
+		// the product of the factors is an associate of p
+		var prod, expected ComplexNumber
+		prod.SetOne()
+		for _, pi := range factors {
+			if p != 3 && !pi.IsPrimary() {
+				t.Fatalf("%d: %v is not primary", p, pi)
+			}
+			prod.Mul(&prod, pi)
+		}
+		prod.Normalize(&prod)
+		expected.A0.Set(pb)
+		expected.Normalize(&expected)
+		if !prod.Equal(&expected) {
+			t.Fatalf("%d: the product of %v is not an associate of p", p, factors)
+		}
+
+		// p ≡ 1 mod 3 splits, 3 ramifies, and the other primes are inert
+		want := 1
+		if p%3 != 2 {
+			want = 2
+		}
+		if len(factors) != want {
+			t.Fatalf("%d: expected %d factors, got %v", p, want, factors)
+		}
+	}
+	for _, p := range []int64{-7, 0, 1} {
+		if _, err := FactorPrime(big.NewInt(p)); !errors.Is(err, ErrNotPrime) {
+			t.Fatalf("%d: expected ErrNotPrime, got %v", p, err)
+		}
+	}
+}
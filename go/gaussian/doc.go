@@ -0,0 +1,12 @@
+// Package gaussian provides Gaussian integer arithmetic.
+//
+// The Gaussian integers form a commutative ring of algebraic integers in the
+// algebraic number field Q(i) – the fourth cyclotomic field.  These are of the
+// form z = a + bi, where a and b are integers and i is a primitive fourth root
+// of unity i.e. i²+1 = 0.
+//
+// It mirrors the eisenstein package for the quartic residue symbol, which
+// gives the Tate pairings of order 4 of curves with 4-torsion points.
+// QuarticSymbolContext computes quartic residue symbols modulo a fixed
+// Gaussian prime with a fixed-width Gaussian GCD.
+package gaussian
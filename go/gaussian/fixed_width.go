@@ -0,0 +1,205 @@
+package gaussian
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Fixed-width signed integers for the Gaussian GCD of QuarticSymbolContext,
+// as in the eisenstein package. The components of the remainders of a
+// Gaussian prime of norm smaller than 2³⁸⁴ fit in signed256.
+
+// signed256 represents a signed 256-bit integer using 4 uint64 words.
+type signed256 struct {
+	w0, w1, w2, w3 uint64
+	neg            bool
+}
+
+func (s signed256) isZero() bool {
+	return s.w0 == 0 && s.w1 == 0 && s.w2 == 0 && s.w3 == 0
+}
+
+func neg256(s signed256) signed256 {
+	if s.isZero() {
+		return s
+	}
+	return signed256{s.w0, s.w1, s.w2, s.w3, !s.neg}
+}
+
+func cmpAbs256(a, b signed256) int {
+	if a.w3 != b.w3 {
+		if a.w3 > b.w3 {
+			return 1
+		}
+		return -1
+	}
+	if a.w2 != b.w2 {
+		if a.w2 > b.w2 {
+			return 1
+		}
+		return -1
+	}
+	if a.w1 != b.w1 {
+		if a.w1 > b.w1 {
+			return 1
+		}
+		return -1
+	}
+	if a.w0 != b.w0 {
+		if a.w0 > b.w0 {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+func add256(a, b signed256) signed256 {
+	if a.neg == b.neg {
+		w0, c := bits.Add64(a.w0, b.w0, 0)
+		w1, c := bits.Add64(a.w1, b.w1, c)
+		w2, c := bits.Add64(a.w2, b.w2, c)
+		w3, _ := bits.Add64(a.w3, b.w3, c)
+		return signed256{w0, w1, w2, w3, a.neg}
+	}
+	if cmpAbs256(a, b) >= 0 {
+		w0, bw := bits.Sub64(a.w0, b.w0, 0)
+		w1, bw := bits.Sub64(a.w1, b.w1, bw)
+		w2, bw := bits.Sub64(a.w2, b.w2, bw)
+		w3, _ := bits.Sub64(a.w3, b.w3, bw)
+		return signed256{w0, w1, w2, w3, a.neg}
+	}
+	w0, bw := bits.Sub64(b.w0, a.w0, 0)
+	w1, bw := bits.Sub64(b.w1, a.w1, bw)
+	w2, bw := bits.Sub64(b.w2, a.w2, bw)
+	w3, _ := bits.Sub64(b.w3, a.w3, bw)
+	return signed256{w0, w1, w2, w3, b.neg}
+}
+
+func sub256(a, b signed256) signed256 { return add256(a, neg256(b)) }
+
+func mulSmall256(s signed256, k int64) signed256 {
+	neg := s.neg
+	if k < 0 {
+		neg = !neg
+		k = -k
+	}
+	if k == 0 {
+		return signed256{}
+	}
+	uk := uint64(k)
+	h0, l0 := bits.Mul64(s.w0, uk)
+	h1, l1 := bits.Mul64(s.w1, uk)
+	h2, l2 := bits.Mul64(s.w2, uk)
+	_, l3 := bits.Mul64(s.w3, uk)
+
+	w0 := l0
+	w1, c := bits.Add64(l1, h0, 0)
+	w2, c := bits.Add64(l2, h1, c)
+	w3, _ := bits.Add64(l3, h2, c)
+
+	return signed256{w0, w1, w2, w3, neg}
+}
+
+func s256ToFloat(s signed256) float64 {
+	f := float64(s.w3)*0x1p192 + float64(s.w2)*0x1p128 + float64(s.w1)*0x1p64 + float64(s.w0)
+	if s.neg {
+		f = -f
+	}
+	return f
+}
+
+func bigToS256(s *signed256, x *big.Int) {
+	s.neg = x.Sign() < 0
+	w := x.Bits()
+	s.w0, s.w1, s.w2, s.w3 = 0, 0, 0, 0
+	if len(w) > 0 {
+		s.w0 = uint64(w[0])
+	}
+	if len(w) > 1 {
+		s.w1 = uint64(w[1])
+	}
+	if len(w) > 2 {
+		s.w2 = uint64(w[2])
+	}
+	if len(w) > 3 {
+		s.w3 = uint64(w[3])
+	}
+}
+
+// rsh1_256 divides s by 2, which must be even.
+func rsh1_256(s signed256) signed256 {
+	return signed256{
+		s.w0>>1 | s.w1<<63,
+		s.w1>>1 | s.w2<<63,
+		s.w2>>1 | s.w3<<63,
+		s.w3 >> 1,
+		s.neg,
+	}
+}
+
+// mod16_256 returns s mod 16 in [0,15].
+func mod16_256(s signed256) uint64 {
+	v := s.w0 & 15
+	if s.neg {
+		v = (16 - v) & 15
+	}
+	return v
+}
+
+// --- Two's complement arithmetic modulo 2²⁵⁶ ---
+
+// mulLow4 returns ±q·s mod 2²⁵⁶ in two's complement, where q is a magnitude
+// of sign neg.
+func mulLow4(q *[4]uint64, neg bool, s signed256) [4]uint64 {
+	b := [4]uint64{s.w0, s.w1, s.w2, s.w3}
+	var z [4]uint64
+	for i := range q {
+		var carry uint64
+		for j := 0; i+j < 4; j++ {
+			hi, lo := bits.Mul64(q[i], b[j])
+			var c uint64
+			lo, c = bits.Add64(lo, z[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			z[i+j] = lo
+			carry = hi
+		}
+	}
+	if neg != s.neg {
+		z = neg4(z)
+	}
+	return z
+}
+
+func add4(a, b [4]uint64) [4]uint64 {
+	var c uint64
+	a[0], c = bits.Add64(a[0], b[0], 0)
+	a[1], c = bits.Add64(a[1], b[1], c)
+	a[2], c = bits.Add64(a[2], b[2], c)
+	a[3], _ = bits.Add64(a[3], b[3], c)
+	return a
+}
+
+func sub4(a, b [4]uint64) [4]uint64 {
+	var bw uint64
+	a[0], bw = bits.Sub64(a[0], b[0], 0)
+	a[1], bw = bits.Sub64(a[1], b[1], bw)
+	a[2], bw = bits.Sub64(a[2], b[2], bw)
+	a[3], _ = bits.Sub64(a[3], b[3], bw)
+	return a
+}
+
+func neg4(a [4]uint64) [4]uint64 {
+	return sub4([4]uint64{}, a)
+}
+
+// fromTwos4 converts a 256-bit two's complement integer to a signed256.
+func fromTwos4(a [4]uint64) signed256 {
+	if a[3]>>63 == 0 {
+		return signed256{a[0], a[1], a[2], a[3], false}
+	}
+	a = neg4(a)
+	return signed256{a[0], a[1], a[2], a[3], true}
+}
@@ -0,0 +1,61 @@
+package gaussian
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrInvalidPrime means that p, or the norm of β, is not a prime p ≡ 1 mod 4.
+var ErrInvalidPrime = errors.New("not a prime p ≡ 1 mod 4")
+
+// PrimeAbove returns the Gaussian prime β = a + bi of norm p which is primary,
+// i.e. β ≡ 1 mod (1+i)³, and such that b > 0. The other primary prime of norm
+// p is its conjugate.
+//
+// β is the GCD of p and ζ - i in ℤ[i], where ζ is a square root of -1 modulo
+// p, so that i ≡ ζ or -ζ mod β.
+//
+// It returns ErrInvalidPrime if p is not a prime p ≡ 1 mod 4.
+func PrimeAbove(p *big.Int) (*ComplexNumber, error) {
+	var t big.Int
+	if p.Sign() <= 0 || t.Mod(p, big.NewInt(4)).Uint64() != 1 || !p.ProbablyPrime(20) {
+		return nil, ErrInvalidPrime
+	}
+
+	// ζ = g^((p-1)/4), with ζ² = -1 for the first g that is not a square
+	var exp, zeta, sq big.Int
+	exp.Rsh(p, 2)
+	t.Sub(p, big.NewInt(1))
+	for g := int64(2); ; g++ {
+		zeta.Exp(big.NewInt(g), &exp, p)
+		if sq.Mul(&zeta, &zeta).Mod(&sq, p).Cmp(&t) == 0 {
+			break
+		}
+	}
+
+	// Euclid's algorithm on p and ζ - i: the remainders have decreasing norms
+	var x, y, q ComplexNumber
+	x.A0.Set(p)
+	y.A0.Set(&zeta)
+	y.A1.SetInt64(-1)
+	r0, r1 := &x, &y
+	for r1.A0.Sign() != 0 || r1.A1.Sign() != 0 {
+		q.Quo(r0, r1)
+		q.Mul(&q, r1)
+		r0.Sub(r0, &q)
+		r0, r1 = r1, r0
+	}
+
+	beta := new(ComplexNumber)
+	beta.Set(r0)
+	if beta.Norm(&t).Cmp(p) != 0 {
+		return nil, ErrInvalidPrime
+	}
+	if _, err := beta.MakePrimary(beta); err != nil {
+		return nil, err
+	}
+	if beta.A1.Sign() < 0 {
+		beta.Conjugate(beta)
+	}
+	return beta, nil
+}
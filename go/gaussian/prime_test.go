@@ -0,0 +1,81 @@
+package gaussian
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+// primes p ≡ 1 mod 4 of the curves
+var curvePrimes = []struct {
+	name string
+	p    string
+}{
+	{"BLS12-377 p", "258664426012969094010652733694893533536393512754914660539884262666720468348340822774968888139573360124440321458177"},
+	{"BLS12-381 r", "52435875175126190479447740508185965837690552500527637822603658699938581184513"},
+}
+
+func TestPrimeAbove(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("PrimeAbove should output a primary prime of norm p", prop.ForAll(
+		func(p *big.Int) bool {
+			beta, err := PrimeAbove(p)
+			if err != nil {
+				return false
+			}
+			var norm big.Int
+			return beta.Norm(&norm).Cmp(p) == 0 && beta.A1.Sign() > 0 && beta.IsPrimary()
+		},
+		GenPrime1Mod4(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	for _, curve := range curvePrimes {
+		var p big.Int
+		p.SetString(curve.p, 10)
+		beta, err := PrimeAbove(&p)
+		if err != nil || beta.Norm(new(big.Int)).Cmp(&p) != 0 || !beta.IsPrimary() {
+			t.Fatalf("%s: unexpected prime %v, %v", curve.name, beta, err)
+		}
+	}
+
+	// small primes
+	for _, p := range []int64{5, 13, 17, 29, 37, 41} {
+		beta, err := PrimeAbove(big.NewInt(p))
+		if err != nil || beta.Norm(new(big.Int)).Int64() != p {
+			t.Fatalf("%d: unexpected prime %v, %v", p, beta, err)
+		}
+	}
+	for _, p := range []int64{-5, 0, 1, 2, 3, 7, 25, 45, 65} {
+		if _, err := PrimeAbove(big.NewInt(p)); !errors.Is(err, ErrInvalidPrime) {
+			t.Fatalf("%d: expected ErrInvalidPrime, got %v", p, err)
+		}
+	}
+}
+
+// GenPrime1Mod4 generates a random prime p ≡ 1 mod 4 of up to 384 bits
+func GenPrime1Mod4() gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		size := 3 + genParams.Rng.Intn(382)
+		for {
+			p, _ := rand.Prime(genParams.Rng, size)
+			if p.Bit(1) == 0 {
+				return gopter.NewGenResult(p, gopter.NoShrinker)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package gaussian
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrDivisibleBy1PlusI means that a Gaussian integer is divisible by the
+	// ramified prime 1+i, e.g. it is zero.
+	ErrDivisibleBy1PlusI = errors.New("divisible by 1+i")
+
+	// ErrNotCoprime means that α and β are not coprime, i.e. [α/β]₄ = 0.
+	ErrNotCoprime = errors.New("α and β are not coprime")
+)
+
+// IsPrimary returns true if z is primary, i.e. z ≡ 1 mod (1+i)³: z[0] is
+// odd, z[1] is even and z[0] + z[1] ≡ 1 mod 4.
+func (z *ComplexNumber) IsPrimary() bool {
+	r0 := mod4(&z.A0)
+	r1 := mod4(&z.A1)
+	return r1%2 == 0 && (r0+r1)%4 == 1
+}
+
+// MakePrimary sets z to the primary associate of x, and returns k such that
+// x = iᵏ·z.
+//
+// It returns ErrDivisibleBy1PlusI if 1+i divides x, in which case x has no
+// primary associate and z is not modified.
+func (z *ComplexNumber) MakePrimary(x *ComplexNumber) (int, error) {
+	r0 := mod4(&x.A0)
+	r1 := mod4(&x.A1)
+	if (r0+r1)%2 == 0 {
+		return 0, ErrDivisibleBy1PlusI
+	}
+
+	// z = i⁻ᵏ·x, with
+	//
+	//	i⁻¹·x = x[1] - x[0]i
+	//	i⁻²·x = -x[0] - x[1]i
+	//	i⁻³·x = -x[1] + x[0]i
+	k := 0
+	switch {
+	case r1%2 == 0 && (r0+r1)%4 == 1:
+		z.Set(x)
+	case r0%2 == 0 && (r1+4-r0)%4 == 1:
+		k = 1
+		z.t0.Set(&x.A1)
+		z.A1.Neg(&x.A0)
+		z.A0.Set(&z.t0)
+	case r1%2 == 0:
+		k = 2
+		z.Neg(x)
+	default:
+		k = 3
+		z.t0.Neg(&x.A1)
+		z.A1.Set(&x.A0)
+		z.A0.Set(&z.t0)
+	}
+	return k, nil
+}
+
+// Valuation1PlusI sets z to x/(1+i)ᵐ, where m is the largest integer such
+// that (1+i)ᵐ divides x, and returns m. It returns -1 if x is zero, in which
+// case z is set to zero.
+//
+// We use:
+//
+//	(x / (1+i))[0] = (x[0] + x[1]) / 2
+//	(x / (1+i))[1] = (x[1] - x[0]) / 2
+//
+// which is integral iff x[0] ≡ x[1] mod 2.
+func (z *ComplexNumber) Valuation1PlusI(x *ComplexNumber) int {
+	z.Set(x)
+	if z.A0.Sign() == 0 && z.A1.Sign() == 0 {
+		return -1
+	}
+	m := 0
+	for z.A0.Bit(0) == z.A1.Bit(0) {
+		z.t0.Add(&z.A0, &z.A1)
+		z.t1.Sub(&z.A1, &z.A0)
+		z.A0.Rsh(&z.t0, 1)
+		z.A1.Rsh(&z.t1, 1)
+		m++
+	}
+	return m
+}
+
+// QuarticResidueSymbol returns k in {0, 1, 2, 3} such that the quartic residue
+// symbol [α/β]₄ is iᵏ. For a prime β, [α/β]₄ ≡ α^((N(β)-1)/4) mod β; it is
+// extended multiplicatively to the other β, with [α/u]₄ = 1 for a unit u. The
+// symbol only depends on the ideal (β), so that β can be any associate.
+//
+// It returns ErrDivisibleBy1PlusI if 1+i divides β, and ErrNotCoprime if α and
+// β are not coprime, i.e. if [α/β]₄ = 0.
+func QuarticResidueSymbol(alpha, beta *ComplexNumber) (int, error) {
+	var a, b, gamma ComplexNumber
+	a.Set(alpha)
+	if _, err := b.MakePrimary(beta); err != nil {
+		return 0, err
+	}
+
+	k := uint64(0)
+	for {
+		// Base cases: if α = 1 or β = 1 return result. -1 is not always a
+		// fourth power, but it is not primary and is only reached as α in
+		// the first iteration.
+		if isOne(&a) || isOne(&b) {
+			return int(k), nil
+		}
+
+		// q = ⌊α/β⌉
+		// γ = α - q * β
+		gamma.Quo(&a, &b)
+		gamma.Mul(&gamma, &b)
+		gamma.Sub(&a, &gamma)
+
+		// Remove ramified factors: γ = (1+i)ᵐ·γ'
+		m := gamma.Valuation1PlusI(&gamma)
+		if m < 0 {
+			return 0, ErrNotCoprime
+		}
+
+		// Make primary: γ' = iⁿ·γ''
+		n, _ := gamma.MakePrimary(&gamma)
+
+		// The supplementary laws for the primary β give
+		// [γ/β]₄ = i^exp·[γ''/β]₄, where
+		// 		exp = m * (β[0] - β[1] - β[1]^2 - 1) / 4 - n * (β[0] - 1) / 2
+		b0 := mod16(&b.A0)
+		b1 := mod16(&b.A1)
+		k += uint64(m) % 4 * ((b0 + 32 - b1 - b1*b1%16 - 1) % 16 / 4)
+		k += uint64(n) * (4 - (b0+15)%8/2)
+
+		// Quartic reciprocity: [γ''/β]₄ = [β/γ'']₄·(-1)^((N(β)-1)/4·(N(γ'')-1)/4)
+		// for γ'' and β primary, where (N(β)-1)/4 ≡ β[1]/2 mod 2.
+		if b1&2 != 0 && mod4(&gamma.A1)&2 != 0 {
+			k += 2
+		}
+		k %= 4
+
+		a.Set(&b)
+		b.Set(&gamma)
+	}
+}
+
+// isOne returns true if z = 1.
+func isOne(z *ComplexNumber) bool {
+	return z.A1.Sign() == 0 && z.A0.IsInt64() && z.A0.Int64() == 1
+}
+
+// mod4 returns z mod 4 in [0,3].
+func mod4(z *big.Int) uint64 {
+	return modPow2(z, 4)
+}
+
+// mod16 returns z mod 16 in [0,15].
+func mod16(z *big.Int) uint64 {
+	return modPow2(z, 16)
+}
+
+// modPow2 returns z mod m in [0,m-1] for m a power of 2 that fits in a word.
+func modPow2(z *big.Int, m uint64) uint64 {
+	w := z.Bits()
+	if len(w) == 0 {
+		return 0
+	}
+	v := uint64(w[0]) & (m - 1)
+	if z.Sign() < 0 {
+		v = (m - v) & (m - 1)
+	}
+	return v
+}
@@ -0,0 +1,220 @@
+package gaussian
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestPrimary(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("MakePrimary should output a primary associate", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b, c ComplexNumber
+			k, err := b.MakePrimary(a)
+			if a.A0.Bit(0) == a.A1.Bit(0) {
+				return errors.Is(err, ErrDivisibleBy1PlusI)
+			}
+			if err != nil || !b.IsPrimary() || k < 0 || k > 3 {
+				return false
+			}
+			// a = i^k·b
+			c.Set(&b)
+			for j := 0; j < k; j++ {
+				c.Mul(&c, imaginaryUnit())
+			}
+			return c.Equal(a)
+		},
+		genE,
+	))
+
+	properties.Property("Having the receiver as operand (MakePrimary) should output the same result", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			k1, err1 := b.MakePrimary(a)
+			k2, err2 := a.MakePrimary(a)
+			return k1 == k2 && errors.Is(err1, err2) && (err1 != nil || a.Equal(&b))
+		},
+		genE,
+	))
+
+	properties.Property("Valuation1PlusI should output the (1+i)-valuation", prop.ForAll(
+		func(a *ComplexNumber, m uint8) bool {
+			var b, c ComplexNumber
+			if _, err := b.MakePrimary(a); err != nil {
+				return true
+			}
+			// c = a·(1+i)^m
+			c.Set(a)
+			for j := uint8(0); j < m%16; j++ {
+				c.Mul(&c, onePlusI())
+			}
+			got := c.Valuation1PlusI(&c)
+			return got == int(m%16) && c.Equal(a)
+		},
+		genE,
+		gopter.Gen(func(genParams *gopter.GenParameters) *gopter.GenResult {
+			return gopter.NewGenResult(uint8(genParams.Rng.Intn(256)), gopter.NoShrinker)
+		}),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	var zero, z ComplexNumber
+	if m := z.Valuation1PlusI(&zero); m != -1 || !z.Equal(&zero) {
+		t.Fatalf("expected -1 for the valuation of 0, got %d", m)
+	}
+	if _, err := z.MakePrimary(&zero); !errors.Is(err, ErrDivisibleBy1PlusI) {
+		t.Fatalf("expected ErrDivisibleBy1PlusI, got %v", err)
+	}
+}
+
+func TestQuarticResidueSymbol(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genC := GenQuarticSymbolContext()
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("QuarticResidueSymbol should output same result as Exp by (p-1)/4", prop.ForAll(
+		func(c *QuarticSymbolContext, a *ComplexNumber) bool {
+			k, err := QuarticResidueSymbol(a, c.Prime())
+
+			// a ≡ a[0] + a[1]·i mod β
+			var x, sym, ik big.Int
+			x.Mul(&a.A1, &c.i).Add(&x, &a.A0).Mod(&x, &c.norm)
+			if x.Sign() == 0 {
+				return errors.Is(err, ErrNotCoprime)
+			}
+			sym.Exp(&x, &c.exp, &c.norm)
+			ik.Exp(&c.i, big.NewInt(int64(k)), &c.norm)
+			return err == nil && sym.Cmp(&ik) == 0
+		},
+		genC,
+		genE,
+	))
+
+	properties.Property("QuarticResidueSymbol should output same result as QuarticSymbolContext", prop.ForAll(
+		func(c *QuarticSymbolContext, x *big.Int) bool {
+			var a ComplexNumber
+			a.A0.Set(x)
+			k, err := QuarticResidueSymbol(&a, c.Prime())
+			sym := c.Symbol(x)
+			if errors.Is(err, ErrNotCoprime) {
+				return sym == 0
+			}
+			return err == nil && k == int(sym)
+		},
+		genC,
+		GenNumber(384),
+	))
+
+	properties.Property("QuarticResidueSymbol should be the same for the associates of β", prop.ForAll(
+		func(c *QuarticSymbolContext, a *ComplexNumber) bool {
+			var beta ComplexNumber
+			beta.Mul(c.Prime(), imaginaryUnit())
+			k1, err1 := QuarticResidueSymbol(a, c.Prime())
+			k2, err2 := QuarticResidueSymbol(a, &beta)
+			return k1 == k2 && errors.Is(err1, err2)
+		},
+		genC,
+		genE,
+	))
+
+	properties.Property("QuarticResidueSymbol should be multiplicative in β", prop.ForAll(
+		func(c1, c2 *QuarticSymbolContext, a *ComplexNumber) bool {
+			var beta ComplexNumber
+			beta.Mul(c1.Prime(), c2.Prime())
+			k, err := QuarticResidueSymbol(a, &beta)
+			k1, err1 := QuarticResidueSymbol(a, c1.Prime())
+			k2, err2 := QuarticResidueSymbol(a, c2.Prime())
+			if err1 != nil || err2 != nil {
+				return errors.Is(err, ErrNotCoprime)
+			}
+			return err == nil && k == (k1+k2)%4
+		},
+		genC,
+		genC,
+		genE,
+	))
+
+	properties.Property("QuarticResidueSymbol should satisfy the quartic reciprocity", prop.ForAll(
+		func(c1, c2 *QuarticSymbolContext) bool {
+			k1, err1 := QuarticResidueSymbol(c1.Prime(), c2.Prime())
+			k2, err2 := QuarticResidueSymbol(c2.Prime(), c1.Prime())
+			if err1 != nil || err2 != nil {
+				return errors.Is(err1, err2)
+			}
+			// (-1)^((N(β₁)-1)/4·(N(β₂)-1)/4)
+			if c1.norm.Bit(2) == 1 && c2.norm.Bit(2) == 1 {
+				k2 = (k2 + 2) % 4
+			}
+			return k1 == k2
+		},
+		genC,
+		genC,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// units, and β divisible by 1+i
+	var a, beta ComplexNumber
+	a.A0.SetInt64(5)
+	beta.SetOne()
+	if k, err := QuarticResidueSymbol(&a, &beta); err != nil || k != 0 {
+		t.Fatalf("expected [5/1]₄ = 1, got %d, %v", k, err)
+	}
+	for _, beta := range []*ComplexNumber{new(ComplexNumber), onePlusI()} {
+		if _, err := QuarticResidueSymbol(&a, beta); !errors.Is(err, ErrDivisibleBy1PlusI) {
+			t.Fatalf("expected ErrDivisibleBy1PlusI, got %v", err)
+		}
+	}
+}
+
+func imaginaryUnit() *ComplexNumber {
+	var z ComplexNumber
+	z.A1.SetInt64(1)
+	return &z
+}
+
+func onePlusI() *ComplexNumber {
+	var z ComplexNumber
+	z.A0.SetInt64(1)
+	z.A1.SetInt64(1)
+	return &z
+}
+
+// bench
+func BenchmarkQuarticResidueSymbol(b *testing.B) {
+	var p big.Int
+	p.SetString(curvePrimes[0].p, 10)
+	beta, _ := PrimeAbove(&p)
+	var alpha ComplexNumber
+	alpha.A0.SetString("2929494998551518193999723405412053246602204569353345363675794262224468384146017685416659704346369932930853282892458", 10)
+
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		QuarticResidueSymbol(&alpha, beta)
+	}
+}
@@ -0,0 +1,351 @@
+package gaussian
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// ErrPrimeTooLarge means that the norm of β is not smaller than 2³⁸⁴.
+var ErrPrimeTooLarge = errors.New("norm of β is not smaller than 2³⁸⁴")
+
+// A QuarticSymbolContext computes quartic residue symbols [x/β]₄ modulo a
+// fixed Gaussian prime β = a + bi of norm p ≡ 1 mod 4, with ℤ[i]/(β) ≅ 𝔽p.
+//
+// The symbol is computed with a two-phase approach:
+//   - Phase 1: one Euclidean step to reduce x from ~log₂(p) bits to ~log₂(p)/2
+//     bits, with fixed-width Barrett-style reciprocals of p when p > 2²⁵⁶
+//   - Phase 2: Gaussian GCD loop using fixed-width signed256 arithmetic
+//
+// A QuarticSymbolContext is safe for concurrent use.
+type QuarticSymbolContext struct {
+	betaA, betaB big.Int // β = a + bi, primary: β ≡ 1 mod (1+i)³
+	norm         big.Int // N(β) = a² + b² = p
+	i            big.Int // i mod β, i.e. -a/b mod p
+	exp          big.Int // (p-1)/4, for the fallback
+
+	beta256A0, beta256A1 signed256 // a and b as signed256
+
+	// Precomputed constants for the fixed-width first reduction modulo β: the
+	// quotient q = round(x·conj(β)/p) is approximated by
+	// |q_re| = round(x·muRe/2³⁸⁴) and |q_im| = round(x·muIm/2³⁸⁴), with
+	// muRe = ⌊|a|·2³⁸⁴/p⌋ and muIm = ⌊|b|·2³⁸⁴/p⌋. Since x < 2³⁸⁴ the
+	// approximation is off by at most 1, and any quotient gives a valid
+	// remainder.
+	fixedWidth       bool // muRe and muIm fit in 256 bits
+	muRe, muIm       [4]uint64
+	muReNeg, muImNeg bool // signs of q_re and q_im, i.e. of a and -b
+}
+
+// NewQuarticSymbolContext returns a context for the quartic residue symbol
+// modulo β = betaA + betaB·i. β can be any associate: it is made primary.
+//
+// It returns ErrInvalidPrime if N(β) is not a prime p ≡ 1 mod 4, and
+// ErrPrimeTooLarge if p ≥ 2³⁸⁴.
+func NewQuarticSymbolContext(betaA, betaB *big.Int) (*QuarticSymbolContext, error) {
+	c := new(QuarticSymbolContext)
+
+	var beta ComplexNumber
+	beta.A0.Set(betaA)
+	beta.A1.Set(betaB)
+	beta.Norm(&c.norm)
+
+	if c.norm.BitLen() > 384 {
+		return nil, ErrPrimeTooLarge
+	}
+	// p = 2 is ramified, and the symbol is not defined modulo (1+i)
+	if c.norm.Cmp(big.NewInt(2)) == 0 || !c.norm.ProbablyPrime(20) {
+		return nil, ErrInvalidPrime
+	}
+
+	// the supplementary laws of the GCD are those of a primary β
+	if _, err := beta.MakePrimary(&beta); err != nil {
+		return nil, ErrInvalidPrime
+	}
+	c.betaA.Set(&beta.A0)
+	c.betaB.Set(&beta.A1)
+
+	// β = a + b·i ≡ 0 mod β, and 𝔽p ≅ ℤ[i]/(β)
+	c.i.ModInverse(&c.betaB, &c.norm)
+	c.i.Mul(&c.i, &c.betaA).Neg(&c.i).Mod(&c.i, &c.norm)
+	c.exp.Rsh(&c.norm, 2)
+
+	bigToS256(&c.beta256A0, &c.betaA)
+	bigToS256(&c.beta256A1, &c.betaB)
+
+	// μ = ⌊|c|·2³⁸⁴/p⌋ for c = a and c = -b. Since |c| < √p, μ < 2²⁵⁶ for
+	// p > 2²⁵⁶; for smaller p phase 1 is done with big.Int
+	var muRe, muIm, t big.Int
+	c.muReNeg = c.betaA.Sign() < 0
+	muRe.Lsh(t.Abs(&c.betaA), 384).Quo(&muRe, &c.norm)
+	c.muImNeg = c.betaB.Sign() > 0
+	muIm.Lsh(t.Abs(&c.betaB), 384).Quo(&muIm, &c.norm)
+	if muRe.BitLen() <= 256 && muIm.BitLen() <= 256 {
+		var mu signed256
+		bigToS256(&mu, &muRe)
+		c.muRe = [4]uint64{mu.w0, mu.w1, mu.w2, mu.w3}
+		bigToS256(&mu, &muIm)
+		c.muIm = [4]uint64{mu.w0, mu.w1, mu.w2, mu.w3}
+		c.fixedWidth = true
+	}
+
+	return c, nil
+}
+
+// Prime returns the primary associate of β.
+func (c *QuarticSymbolContext) Prime() *ComplexNumber {
+	var z ComplexNumber
+	z.A0.Set(&c.betaA)
+	z.A1.Set(&c.betaB)
+	return &z
+}
+
+// Modulus returns p = N(β).
+func (c *QuarticSymbolContext) Modulus() *big.Int {
+	return new(big.Int).Set(&c.norm)
+}
+
+// I returns the image of i in 𝔽p ≅ ℤ[i]/(β), i.e. -a/b mod p.
+func (c *QuarticSymbolContext) I() *big.Int {
+	return new(big.Int).Set(&c.i)
+}
+
+// Symbol returns the quartic residue symbol of x modulo β: 0 (symbol=1,
+// quartic residue, or x ≡ 0 mod p), 1 (symbol=i), 2 (symbol=-1), or 3
+// (symbol=-i).
+func (c *QuarticSymbolContext) Symbol(x *big.Int) uint8 {
+	if c.fixedWidth && x.Sign() >= 0 && x.BitLen() <= 384 {
+		var words [6]uint64
+		for i, w := range x.Bits() {
+			words[i] = uint64(w)
+		}
+		return c.SymbolWords(&words)
+	}
+
+	var r big.Int
+	r.Mod(x, &c.norm)
+	if r.Sign() == 0 {
+		return 0
+	}
+	e0, e1 := c.reduceBetaBig(&r)
+	if sym, ok := c.symbolFromRem(e0, e1); ok {
+		return sym
+	}
+	return c.fallback(&r)
+}
+
+// SymbolWords is like Symbol for 0 ≤ x < 2³⁸⁴ given as little-endian 64-bit
+// words, as returned by the Bits method of the fp.Element types. It doesn't
+// allocate when p > 2²⁵⁶.
+func (c *QuarticSymbolContext) SymbolWords(x *[6]uint64) uint8 {
+	if !c.fixedWidth {
+		return c.Symbol(wordsToBig(x))
+	}
+	if x[0]|x[1]|x[2]|x[3]|x[4]|x[5] == 0 {
+		return 0
+	}
+
+	// Phase 1: compute first remainder = (x, 0) mod β
+	e0, e1 := c.reduceBeta(x)
+
+	if sym, ok := c.symbolFromRem(e0, e1); ok {
+		return sym
+	}
+	return c.fallback(wordsToBig(x))
+}
+
+// symbolFromRem runs phase 2 of Symbol from the first remainder (e0, e1) of x
+// modulo β, see QuarticResidueSymbol. It returns false if the GCD fails, in
+// which case the symbol is computed by fallback.
+func (c *QuarticSymbolContext) symbolFromRem(e0, e1 signed256) (uint8, bool) {
+	a0, a1 := e0, e1
+	b0, b1 := c.beta256A0, c.beta256A1
+	k := uint64(0)
+
+	for iter := 0; ; iter++ {
+		if iter > 1000 {
+			return 0, false
+		}
+
+		// γ = α mod β; in the first iteration α = e is already reduced
+		g0, g1 := a0, a1
+		if iter > 0 {
+			var ok bool
+			g0, g1, ok = gaussRem256(a0, a1, b0, b1)
+			if !ok {
+				return 0, false
+			}
+		}
+		if g0.isZero() && g1.isZero() {
+			return 0, true
+		}
+
+		// Remove ramified factors: γ = (1+i)ᵐ·γ'
+		m := uint64(0)
+		for (g0.w0^g1.w0)&1 == 0 {
+			g0, g1 = rsh1_256(add256(g0, g1)), rsh1_256(sub256(g1, g0))
+			m++
+		}
+
+		// Make primary: γ' = iⁿ·γ''
+		n := uint64(0)
+		for {
+			r0, r1 := mod16_256(g0), mod16_256(g1)
+			if r1%2 == 0 && (r0+r1)%4 == 1 {
+				break
+			}
+			// i⁻¹·γ = γ[1] - γ[0]i
+			g0, g1 = g1, neg256(g0)
+			n++
+		}
+
+		// supplementary laws, see QuarticResidueSymbol
+		r0, r1 := mod16_256(b0), mod16_256(b1)
+		k += m % 4 * ((r0 + 32 - r1 - r1*r1%16 - 1) % 16 / 4)
+		k += n * (4 - (r0+15)%8/2)
+
+		// quartic reciprocity
+		if r1&2 != 0 && mod16_256(g1)&2 != 0 {
+			k += 2
+		}
+		k %= 4
+
+		// [β/γ'']₄ = 1 if γ'' = 1
+		if g1.isZero() && g0.w0 == 1 && g0.w1|g0.w2|g0.w3 == 0 {
+			return uint8(k), true
+		}
+
+		a0, a1 = b0, b1
+		b0, b1 = g0, g1
+	}
+}
+
+// gaussRem256 computes the Gaussian remainder a mod b in ℤ[i], using a float64
+// approximation of the quotient. It returns false if the quotient doesn't fit
+// in an int64.
+func gaussRem256(a0, a1, b0, b1 signed256) (signed256, signed256, bool) {
+	af0 := s256ToFloat(a0)
+	af1 := s256ToFloat(a1)
+	bf0 := s256ToFloat(b0)
+	bf1 := s256ToFloat(b1)
+
+	// q = round(a·conj(b)/N(b)), with
+	// a·conj(b) = (a₀b₀ + a₁b₁) + (a₁b₀ - a₀b₁)i
+	nB := bf0*bf0 + bf1*bf1
+	qf0 := math.Round((af0*bf0 + af1*bf1) / nB)
+	qf1 := math.Round((af1*bf0 - af0*bf1) / nB)
+	if math.Abs(qf0) >= 0x1p62 || math.Abs(qf1) >= 0x1p62 {
+		return signed256{}, signed256{}, false
+	}
+	qr, qi := int64(qf0), int64(qf1)
+
+	// q·b = (qr·b₀ - qi·b₁) + (qr·b₁ + qi·b₀)i
+	qb0 := sub256(mulSmall256(b0, qr), mulSmall256(b1, qi))
+	qb1 := add256(mulSmall256(b1, qr), mulSmall256(b0, qi))
+	return sub256(a0, qb0), sub256(a1, qb1), true
+}
+
+// fallback uses the exponentiation-based quartic character.
+func (c *QuarticSymbolContext) fallback(x *big.Int) uint8 {
+	var sym, t big.Int
+	sym.Exp(x, &c.exp, &c.norm)
+	// x^((p-1)/4) ≡ [x/β]₄ mod β
+	for k := uint8(0); k < 4; k++ {
+		if sym.Cmp(t.Exp(&c.i, big.NewInt(int64(k)), &c.norm)) == 0 {
+			return k
+		}
+	}
+	// x ≡ 0 mod p
+	return 0
+}
+
+// --- Phase 1: reduction modulo β ---
+
+// reduceBeta returns a remainder (e0, e1) of x modulo β, using fixed-width
+// arithmetic.
+func (c *QuarticSymbolContext) reduceBeta(x *[6]uint64) (e0, e1 signed256) {
+	qRe := quarticRoundQuo(x, &c.muRe)
+	qIm := quarticRoundQuo(x, &c.muIm)
+
+	// The remainder is smaller than 2²⁵⁵, so it is computed modulo 2²⁵⁶ in
+	// two's complement:
+	//   e = x - (q_re·a - q_im·b)
+	//   f = -(q_re·b + q_im·a)
+	e := [4]uint64{x[0], x[1], x[2], x[3]}
+	e = sub4(e, mulLow4(&qRe, c.muReNeg, c.beta256A0))
+	e = add4(e, mulLow4(&qIm, c.muImNeg, c.beta256A1))
+	f := mulLow4(&qRe, c.muReNeg, c.beta256A1)
+	f = add4(f, mulLow4(&qIm, c.muImNeg, c.beta256A0))
+	f = neg4(f)
+
+	return fromTwos4(e), fromTwos4(f)
+}
+
+// reduceBetaBig returns a remainder (e0, e1) of 0 ≤ x < p modulo β, using
+// big.Int arithmetic.
+func (c *QuarticSymbolContext) reduceBetaBig(x *big.Int) (e0, e1 signed256) {
+	// q = round(x·conj(β)/p), conj(β) = a - b·i
+	var qRe, qIm, t, u big.Int
+	t.Mul(&c.betaA, x)
+	roundQuo(&qRe, &t, &c.norm)
+	t.Mul(&c.betaB, x).Neg(&t)
+	roundQuo(&qIm, &t, &c.norm)
+
+	// e = x - (q_re·a - q_im·b)
+	// f = -(q_re·b + q_im·a)
+	t.Mul(&qRe, &c.betaA)
+	u.Mul(&qIm, &c.betaB)
+	t.Sub(&t, &u).Sub(x, &t)
+	bigToS256(&e0, &t)
+	t.Mul(&qRe, &c.betaB)
+	u.Mul(&qIm, &c.betaA)
+	t.Add(&t, &u).Neg(&t)
+	bigToS256(&e1, &t)
+
+	return e0, e1
+}
+
+// roundQuo sets z to round(x/d) for d > 0.
+func roundQuo(z, x, d *big.Int) {
+	var t big.Int
+	t.Lsh(x, 1).Add(&t, d)
+	z.Lsh(d, 1)
+	z.Div(&t, z)
+}
+
+// quarticRoundQuo returns round(x·mu/2³⁸⁴), which must be smaller than 2²⁵⁶.
+func quarticRoundQuo(x *[6]uint64, mu *[4]uint64) [4]uint64 {
+	var t [10]uint64
+	for i := range x {
+		var carry uint64
+		for j := range mu {
+			hi, lo := bits.Mul64(x[i], mu[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j] = lo
+			carry = hi
+		}
+		t[i+4] = carry
+	}
+	// + 2³⁸³ to round to the nearest
+	var c uint64
+	t[5], c = bits.Add64(t[5], 1<<63, 0)
+	t[6], c = bits.Add64(t[6], 0, c)
+	t[7], c = bits.Add64(t[7], 0, c)
+	t[8], c = bits.Add64(t[8], 0, c)
+	t[9], _ = bits.Add64(t[9], 0, c)
+	return [4]uint64{t[6], t[7], t[8], t[9]}
+}
+
+// wordsToBig converts little-endian 64-bit words to a big.Int.
+func wordsToBig(x *[6]uint64) *big.Int {
+	w := make([]big.Word, len(x))
+	for i := range x {
+		w[i] = big.Word(x[i])
+	}
+	return new(big.Int).SetBits(w)
+}
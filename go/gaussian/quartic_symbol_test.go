@@ -0,0 +1,283 @@
+package gaussian
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestQuarticSymbolContextSmallPrimes(t *testing.T) {
+	t.Parallel()
+
+	// all the Gaussian primes of small norm, and all the residues
+	for a := int64(-12); a <= 12; a++ {
+		for b := int64(-12); b <= 12; b++ {
+			c, err := NewQuarticSymbolContext(big.NewInt(a), big.NewInt(b))
+			if err != nil {
+				if !errors.Is(err, ErrInvalidPrime) {
+					t.Fatalf("%d+%di: unexpected error %v", a, b, err)
+				}
+				continue
+			}
+			p := c.Modulus()
+			for x := int64(0); x < p.Int64(); x++ {
+				xb := big.NewInt(x)
+				if got, want := c.Symbol(xb), c.fallback(xb); got != want {
+					t.Fatalf("%d+%di: symbol of %d is %d, expected %d", a, b, x, got, want)
+				}
+				// x + p and x - p
+				xb.Add(xb, p)
+				if got, want := c.Symbol(xb), c.fallback(big.NewInt(x)); got != want {
+					t.Fatalf("%d+%di: symbol of %d+p is %d, expected %d", a, b, x, got, want)
+				}
+				xb.Sub(xb, p).Sub(xb, p)
+				if got, want := c.Symbol(xb), c.fallback(big.NewInt(x)); got != want {
+					t.Fatalf("%d+%di: symbol of %d-p is %d, expected %d", a, b, x, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestQuarticSymbolContext(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genC := GenQuarticSymbolContext()
+	genX := GenNumber(384)
+
+	properties.Property("Symbol should output same result as Exp by (p-1)/4", prop.ForAll(
+		func(c *QuarticSymbolContext, x *big.Int) bool {
+			var r big.Int
+			r.Mod(x, &c.norm)
+			return c.Symbol(x) == c.fallback(&r)
+		},
+		genC,
+		genX,
+	))
+
+	properties.Property("Symbol should be multiplicative", prop.ForAll(
+		func(c *QuarticSymbolContext, x, y *big.Int) bool {
+			var xy big.Int
+			xy.Mul(x, y)
+			return c.Symbol(&xy) == (c.Symbol(x)+c.Symbol(y))%4
+		},
+		genC,
+		genX,
+		genX,
+	))
+
+	properties.Property("Symbol should be the same for the associates of β", prop.ForAll(
+		func(c *QuarticSymbolContext, x *big.Int) bool {
+			// i·β = -b + a·i
+			var a big.Int
+			a.Neg(&c.betaB)
+			c2, err := NewQuarticSymbolContext(&a, &c.betaA)
+			return err == nil && c2.Prime().Equal(c.Prime()) &&
+				c2.I().Cmp(c.I()) == 0 && c2.Symbol(x) == c.Symbol(x)
+		},
+		genC,
+		genX,
+	))
+
+	properties.Property("I should be a square root of -1 of symbol i^((p-1)/4)", prop.ForAll(
+		func(c *QuarticSymbolContext) bool {
+			var t big.Int
+			i := c.I()
+			t.Mul(i, i).Add(&t, big.NewInt(1)).Mod(&t, &c.norm)
+			if t.Sign() != 0 {
+				return false
+			}
+			// [i/β]₄ = i^((p-1)/4)
+			return uint64(c.Symbol(i)) == t.Mod(&c.exp, big.NewInt(4)).Uint64()
+		},
+		genC,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestQuarticSymbolContextCurves(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	for _, curve := range curvePrimes {
+		var p big.Int
+		p.SetString(curve.p, 10)
+		beta, err := PrimeAbove(&p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := NewQuarticSymbolContext(&beta.A0, &beta.A1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.fixedWidth != (p.BitLen() > 256) {
+			t.Fatalf("%s: unexpected fixed-width phase 1 %v", curve.name, c.fixedWidth)
+		}
+
+		properties := gopter.NewProperties(parameters)
+
+		genX := GenNumber(384)
+
+		properties.Property("["+curve.name+"] SymbolWords should output same result as Exp by (p-1)/4", prop.ForAll(
+			func(x *big.Int) bool {
+				var r big.Int
+				r.Mod(x, &c.norm)
+				var words [6]uint64
+				for i, w := range x.Bits() {
+					words[i] = uint64(w)
+				}
+				return c.SymbolWords(&words) == c.fallback(&r)
+			},
+			genX,
+		))
+
+		if c.fixedWidth {
+			properties.Property("["+curve.name+"] reduceBeta should output a remainder of x modulo β", prop.ForAll(
+				func(x *big.Int) bool {
+					var words [6]uint64
+					for i, w := range x.Bits() {
+						words[i] = uint64(w)
+					}
+					e0, e1 := c.reduceBeta(&words)
+					return isRemainder(c, x, e0, e1)
+				},
+				genX,
+			))
+		}
+
+		properties.Property("["+curve.name+"] reduceBetaBig should output a remainder of x modulo β", prop.ForAll(
+			func(x *big.Int) bool {
+				var r big.Int
+				r.Mod(x, &c.norm)
+				e0, e1 := c.reduceBetaBig(&r)
+				return isRemainder(c, &r, e0, e1)
+			},
+			genX,
+		))
+
+		properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+		// multiples of p
+		for _, x := range []*big.Int{new(big.Int), c.Modulus(), new(big.Int).Lsh(c.Modulus(), 2)} {
+			if sym := c.Symbol(x); sym != 0 {
+				t.Fatalf("%s: symbol of %v is %d, expected 0", curve.name, x, sym)
+			}
+		}
+	}
+}
+
+func TestNewQuarticSymbolContextErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, beta := range []struct {
+		a, b int64
+		err  error
+	}{
+		{0, 0, ErrInvalidPrime},
+		{1, 1, ErrInvalidPrime}, // 1+i, of norm 2
+		{3, 0, ErrInvalidPrime}, // 3 is inert
+		{4, 2, ErrInvalidPrime}, // norm 20
+	} {
+		if _, err := NewQuarticSymbolContext(big.NewInt(beta.a), big.NewInt(beta.b)); !errors.Is(err, beta.err) {
+			t.Fatalf("%d+%di: expected %v, got %v", beta.a, beta.b, beta.err, err)
+		}
+	}
+
+	var a big.Int
+	a.Lsh(big.NewInt(1), 193)
+	if _, err := NewQuarticSymbolContext(&a, big.NewInt(3)); !errors.Is(err, ErrPrimeTooLarge) {
+		t.Fatalf("expected ErrPrimeTooLarge, got %v", err)
+	}
+}
+
+// isRemainder checks that β divides x - (e0 + e1·i) and that e0 and e1 are
+// smaller than 2²⁵⁵.
+func isRemainder(c *QuarticSymbolContext, x *big.Int, e0, e1 signed256) bool {
+	var u, v big.Int
+	u.Sub(x, s256ToBig(e0))
+	v.Neg(s256ToBig(e1))
+
+	// β | u+vi iff p divides both components of (u+vi)·conj(β):
+	// ua+vb and va-ub.
+	var re, im, t big.Int
+	re.Mul(&u, &c.betaA).Add(&re, t.Mul(&v, &c.betaB))
+	im.Mul(&v, &c.betaA).Sub(&im, t.Mul(&u, &c.betaB))
+	return e0.w3>>63 == 0 && e1.w3>>63 == 0 &&
+		t.Mod(&re, &c.norm).Sign() == 0 && t.Mod(&im, &c.norm).Sign() == 0
+}
+
+// s256ToBig converts s to a big.Int.
+func s256ToBig(s signed256) *big.Int {
+	var z big.Int
+	z.SetBits([]big.Word{big.Word(s.w0), big.Word(s.w1), big.Word(s.w2), big.Word(s.w3)})
+	if s.neg {
+		z.Neg(&z)
+	}
+	return &z
+}
+
+// GenQuarticSymbolContext generates a context for a random Gaussian prime of
+// norm between 2⁸ and 2³⁸⁴
+func GenQuarticSymbolContext() gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		size := 4 + genParams.Rng.Intn(188)
+		var bound big.Int
+		bound.Lsh(big.NewInt(1), uint(size))
+		for {
+			a, _ := rand.Int(genParams.Rng, &bound)
+			b, _ := rand.Int(genParams.Rng, &bound)
+			if genParams.Rng.Intn(2) == 0 {
+				a.Neg(a)
+			}
+			if c, err := NewQuarticSymbolContext(a, b); err == nil {
+				return gopter.NewGenResult(c, gopter.NoShrinker)
+			}
+		}
+	}
+}
+
+// bench
+func BenchmarkQuarticSymbolContext(b *testing.B) {
+	var p big.Int
+	p.SetString(curvePrimes[0].p, 10)
+	beta, _ := PrimeAbove(&p)
+	c, _ := NewQuarticSymbolContext(&beta.A0, &beta.A1)
+
+	var x big.Int
+	x.SetString("2929494998551518193999723405412053246602204569353345363675794262224468384146017685416659704346369932930853282892458", 10)
+	var words [6]uint64
+	for i, w := range x.Bits() {
+		words[i] = uint64(w)
+	}
+
+	b.Run("Symbol", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			c.Symbol(&x)
+		}
+	})
+	b.Run("SymbolWords", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			c.SymbolWords(&words)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons for which a batch is rejected. The curve packages export them and
+// document the orders of the components on their curve.
+var (
+	ErrNotOnCurve        = errors.New("point not on the curve")
+	ErrTorsion2          = errors.New("point with a non-trivial component of order 2")
+	ErrTorsion3          = errors.New("point with a non-trivial component of order 3")
+	ErrRandomCombination = errors.New("random linear combination not in G1")
+)
+
+// Errors due to the options or to the use of a BatchVerifier.
+var (
+	ErrInvalidSecurityLevel = errors.New("invalid security level: must be in [0, MaxSecurityLevel]")
+	ErrStreamingDomainTag   = errors.New("DomainTag is not supported by BatchVerifier")
+	ErrVerified             = errors.New("BatchVerifier already verified")
+)
+
+// Error is the error returned by CheckSubGroupBatch when a batch is rejected.
+type Error struct {
+	// Index is the index of the first offending point, or -1 if it is not
+	// known, as for a random linear combination.
+	Index int
+
+	// Err is the reason of the rejection.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Index < 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("point %d: %v", e.Index, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CombinationError returns the error of CheckSubGroupBatch for the result of
+// the random linear combinations check, where reason is the error wrapped when
+// a combination is not in G1.
+func CombinationError(ok bool, err, reason error) error {
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &Error{Index: -1, Err: reason}
+	}
+	return nil
+}
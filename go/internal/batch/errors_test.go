@@ -0,0 +1,28 @@
+package batch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	t.Parallel()
+
+	err := error(&Error{Index: 3, Err: ErrTorsion2})
+	if !errors.Is(err, ErrTorsion2) || err.Error() != "point 3: "+ErrTorsion2.Error() {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	errFailing := errors.New("failing")
+	if err := CombinationError(false, errFailing, ErrRandomCombination); err != errFailing {
+		t.Fatalf("expected %v, got %v", errFailing, err)
+	}
+	if err := CombinationError(true, nil, ErrRandomCombination); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err = CombinationError(false, nil, ErrRandomCombination)
+	var batchErr *Error
+	if !errors.As(err, &batchErr) || batchErr.Index != -1 || err.Error() != ErrRandomCombination.Error() {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
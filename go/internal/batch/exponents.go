@@ -0,0 +1,65 @@
+// Package batch holds the curve-independent parts of the batch subgroup
+// membership checks.
+package batch
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// TateRounds returns the number of rounds of an aggregated Tate pairings test
+// for a failure probability of 2⁻ᵝ, where β is securityLevel: a round whose
+// exponents are drawn with Bits2 misses a non-trivial character with
+// probability at most 1/2, so β of them are needed, and a round whose
+// exponents are drawn with Trit with probability at most 1/3, so ⌈β/log₂(3)⌉
+// of them are needed.
+func TateRounds(securityLevel int) (roundsBits2, roundsTrit int) {
+	return securityLevel, int(math.Ceil(float64(securityLevel) / math.Log2(3)))
+}
+
+// ExponentReader draws the small random exponents of the aggregated Tate
+// pairings tests from a source of random bytes.
+type ExponentReader struct {
+	rng    io.Reader
+	buf    [1024]byte
+	pos    int    // first unread byte of buf
+	word   uint64 // unread random bits
+	nbBits int    // number of unread bits in word
+}
+
+// NewExponentReader returns an ExponentReader reading from rng, which must
+// always fill its buffer, as crypto/rand and ChaCha8 do.
+func NewExponentReader(rng io.Reader) *ExponentReader {
+	r := &ExponentReader{rng: rng}
+	r.pos = len(r.buf)
+	return r
+}
+
+// Bits2 returns 2 random bits.
+func (r *ExponentReader) Bits2() uint64 {
+	if r.nbBits == 0 {
+		if r.pos == len(r.buf) {
+			r.rng.Read(r.buf[:]) // crypto/rand and ChaCha8 do not return an error, always fill buf
+			r.pos = 0
+		}
+		// buf is read as little-endian uint64 so that the exponents drawn from
+		// a seeded source do not depend on the platform
+		r.word = binary.LittleEndian.Uint64(r.buf[r.pos:])
+		r.pos += 8
+		r.nbBits = 64
+	}
+	e := r.word & 3
+	r.word >>= 2
+	r.nbBits -= 2
+	return e
+}
+
+// Trit returns a uniform value in {0,1,2}, by rejection sampling of 2 bits.
+func (r *ExponentReader) Trit() uint64 {
+	for {
+		if e := r.Bits2(); e != 3 {
+			return e
+		}
+	}
+}
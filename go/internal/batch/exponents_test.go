@@ -0,0 +1,61 @@
+package batch
+
+import (
+	"crypto/rand"
+	mrand "math/rand/v2"
+	"testing"
+)
+
+func TestTateRounds(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		securityLevel, roundsBits2, roundsTrit int
+	}{
+		{1, 1, 1},
+		{60, 60, 38},
+		{64, 64, 41},
+		{128, 128, 81},
+	} {
+		roundsBits2, roundsTrit := TateRounds(tc.securityLevel)
+		if roundsBits2 != tc.roundsBits2 || roundsTrit != tc.roundsTrit {
+			t.Fatalf("security level %d: expected %d and %d rounds, got %d and %d", tc.securityLevel,
+				tc.roundsBits2, tc.roundsTrit, roundsBits2, roundsTrit)
+		}
+	}
+}
+
+func TestExponentReader(t *testing.T) {
+	t.Parallel()
+
+	// the exponents are close to uniform
+	const n = 1 << 15
+	exps := NewExponentReader(rand.Reader)
+	var counts2 [4]int
+	var counts3 [3]int
+	for i := 0; i < n; i++ {
+		counts2[exps.Bits2()]++
+		counts3[exps.Trit()]++
+	}
+	for e, c := range counts2 {
+		if c < n/4-n/32 || c > n/4+n/32 {
+			t.Fatalf("Bits2: %d drawn %d times out of %d", e, c, n)
+		}
+	}
+	for e, c := range counts3 {
+		if c < n/3-n/32 || c > n/3+n/32 {
+			t.Fatalf("Trit: %d drawn %d times out of %d", e, c, n)
+		}
+	}
+
+	// the exponents drawn from a seeded source are the 2-bit little-endian
+	// digits of its bytes
+	var buf [8]byte
+	mrand.NewChaCha8([32]byte{}).Read(buf[:])
+	exps = NewExponentReader(mrand.NewChaCha8([32]byte{}))
+	for i := 0; i < 32; i++ {
+		if e := exps.Bits2(); e != uint64(buf[i/4]>>(2*(i%4))&3) {
+			t.Fatalf("digit %d: expected %d, got %d", i, buf[i/4]>>(2*(i%4))&3, e)
+		}
+	}
+}
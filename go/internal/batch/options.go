@@ -0,0 +1,99 @@
+package batch
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	mrand "math/rand/v2"
+)
+
+// MaxSecurityLevel is the largest soundness in bits accepted by the batch
+// methods.
+const MaxSecurityLevel = 256
+
+// SecurityLevel returns the security level β selected by securityLevel, where
+// zero selects defaultLevel, or ErrInvalidSecurityLevel if it is not in
+// [0, MaxSecurityLevel].
+func SecurityLevel(securityLevel, defaultLevel int) (int, error) {
+	if securityLevel == 0 {
+		securityLevel = defaultLevel
+	}
+	if securityLevel < 0 || securityLevel > MaxSecurityLevel {
+		return 0, ErrInvalidSecurityLevel
+	}
+	return securityLevel, nil
+}
+
+// SplitRounds returns, for each random linear combination, the bit size of
+// its random scalars, when a combination reaches at most bound bits of
+// soundness: rounds=⌈β/bound⌉, the last round only needs the remaining bits.
+func SplitRounds(securityLevel, bound int) []int {
+	rounds := (securityLevel + bound - 1) / bound
+	nbBits := make([]int, rounds)
+	for i := range nbBits {
+		nbBits[i] = min(bound, securityLevel-i*bound)
+	}
+	return nbBits
+}
+
+// RandomSources returns n sources of random bytes, one per chunk of a random
+// linear combination. If rng is nil, every source is crypto/rand.Reader.
+// Otherwise each source is a ChaCha8 stream keyed by a 32-byte seed read, in
+// order, from rng, so that the random scalars only depend on rng and not on
+// the scheduling of the chunks.
+func RandomSources(rng io.Reader, n int) ([]io.Reader, error) {
+	sources := make([]io.Reader, n)
+	if rng == nil {
+		for i := range sources {
+			sources[i] = rand.Reader
+		}
+		return sources, nil
+	}
+	var seed [32]byte
+	for i := range sources {
+		if _, err := io.ReadFull(rng, seed[:]); err != nil {
+			return nil, fmt.Errorf("read random seed: %w", err)
+		}
+		sources[i] = mrand.NewChaCha8(seed)
+	}
+	return sources, nil
+}
+
+// Transcript returns the source of random bytes of the Fiat–Shamir mode, a
+// ChaCha8 stream keyed by
+//
+//	SHA-256(prefix ‖ tag ‖ β ‖ N ‖ RawBytes(P_0) ‖ … ‖ RawBytes(P_{N-1}))
+//
+// where prefix and tag are prefixed by their length, all the integers are
+// encoded as big-endian uint64 and the encodings of the n points are written
+// by writePoint. prefix separates the curves, groups and encodings.
+//
+// Modelling SHA-256 as a random oracle, the random scalars are uniform and
+// independent of the points, so the soundness bound of a batch method holds
+// for one batch. An adversary that can try q batches, e.g. by re-randomizing
+// a point, is accepted with probability at most q·2⁻ᵝ, hence β should also
+// account for the hashing power of the adversary, e.g. β=128.
+func Transcript(prefix string, tag []byte, securityLevel, n int, writePoint func(h hash.Hash, i int)) io.Reader {
+	h := sha256.New()
+	var buf [8]byte
+	writeUint64 := func(v uint64) {
+		binary.BigEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	writeUint64(uint64(len(prefix)))
+	h.Write([]byte(prefix))
+	writeUint64(uint64(len(tag)))
+	h.Write(tag)
+	writeUint64(uint64(securityLevel))
+	writeUint64(uint64(n))
+	for i := 0; i < n; i++ {
+		writePoint(h, i)
+	}
+
+	var seed [32]byte
+	h.Sum(seed[:0])
+	return mrand.NewChaCha8(seed)
+}
@@ -0,0 +1,111 @@
+package batch
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash"
+	mrand "math/rand/v2"
+	"testing"
+)
+
+func TestSecurityLevel(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		securityLevel, expected int
+	}{
+		{0, 60},
+		{1, 1},
+		{128, 128},
+		{MaxSecurityLevel, MaxSecurityLevel},
+	} {
+		if securityLevel, err := SecurityLevel(tc.securityLevel, 60); err != nil || securityLevel != tc.expected {
+			t.Fatalf("security level %d: expected %d, got %d, %v", tc.securityLevel, tc.expected, securityLevel, err)
+		}
+	}
+	for _, securityLevel := range []int{-1, MaxSecurityLevel + 1} {
+		if _, err := SecurityLevel(securityLevel, 60); !errors.Is(err, ErrInvalidSecurityLevel) {
+			t.Fatalf("security level %d: expected ErrInvalidSecurityLevel, got %v", securityLevel, err)
+		}
+	}
+}
+
+func TestSplitRounds(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		securityLevel, bound int
+		roundsBits           []int
+	}{
+		{60, 60, []int{60}},
+		{128, 60, []int{60, 60, 8}},
+		{64, 13, []int{13, 13, 13, 13, 12}},
+		{1, 3, []int{1}},
+	} {
+		roundsBits := SplitRounds(tc.securityLevel, tc.bound)
+		if fmt.Sprint(roundsBits) != fmt.Sprint(tc.roundsBits) {
+			t.Fatalf("security level %d, bound %d: expected %v, got %v", tc.securityLevel, tc.bound, tc.roundsBits, roundsBits)
+		}
+	}
+}
+
+func TestRandomSources(t *testing.T) {
+	t.Parallel()
+
+	sources, err := RandomSources(nil, 2)
+	if err != nil || sources[0] != rand.Reader || sources[1] != rand.Reader {
+		t.Fatalf("expected crypto/rand.Reader, got %v, %v", sources, err)
+	}
+
+	// a seeded source gives the same streams, which differ from each other
+	draw := func() [2][32]byte {
+		sources, err := RandomSources(mrand.NewChaCha8([32]byte{1}), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b [2][32]byte
+		for i := range sources {
+			sources[i].Read(b[i][:])
+		}
+		return b
+	}
+	b := draw()
+	if b != draw() || b[0] == b[1] {
+		t.Fatal("expected reproducible and distinct streams")
+	}
+
+	if _, err := RandomSources(bytes.NewReader(make([]byte, 40)), 2); err == nil {
+		t.Fatal("expected an error for a short source")
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	t.Parallel()
+
+	points := [][]byte{{1}, {2}}
+	draw := func(prefix string, tag []byte, securityLevel int, points [][]byte) [32]byte {
+		var b [32]byte
+		Transcript(prefix, tag, securityLevel, len(points), func(h hash.Hash, i int) {
+			h.Write(points[i])
+		}).Read(b[:])
+		return b
+	}
+	b := draw("G1", []byte("test"), 64, points)
+	if b != draw("G1", []byte("test"), 64, points) {
+		t.Fatal("expected a reproducible stream")
+	}
+	// every input of the transcript is bound
+	for _, other := range [][32]byte{
+		draw("G2", []byte("test"), 64, points),
+		draw("G1", []byte("tesu"), 64, points),
+		draw("G1", []byte("test"), 128, points),
+		draw("G1", []byte("test"), 64, points[:1]),
+		draw("G1", []byte("test"), 64, [][]byte{{2}, {1}}),
+	} {
+		if b == other {
+			t.Fatal("expected different streams")
+		}
+	}
+}
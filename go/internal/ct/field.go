@@ -0,0 +1,138 @@
+// Package ct holds the constant-time field arithmetic of the residue symbols
+// for secret inputs, e.g. in a hash-to-curve of private messages.
+//
+// The symbols use Euler's criterion with a public exponent, so that their
+// running time only depends on the modulus as long as the field multiplication
+// is constant-time. The multiplication of the fp packages is not on every
+// target: the amd64 assembly with ADX and the arm64 assembly select the final
+// subtraction with CMOV and CSEL, but the generic code, used on amd64 without
+// ADX, on the other targets and with the purego tag, only subtracts the
+// modulus when the result is not smaller than it. Field
+// multiplies with a masked final subtraction instead, and only uses the
+// math/bits functions whose running time does not depend on their inputs.
+// In pure Go it is about three times slower than the ADX assembly.
+//
+// A divstep-style Eisenstein GCD with a fixed iteration count, as [BY19] for
+// the Legendre symbol, needs about a thousand masked iterations on 400-bit
+// integers, which costs more than the exponentiation; and the variable-time
+// CubicSymbolFast of the curve packages is not faster than the exponentiation
+// either.
+//
+// [BY19]: https://eprint.iacr.org/2019/266.pdf
+package ct
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// A Field is a prime field 𝔽q with q < 2³⁸³. Its elements are 6 little-endian
+// 64-bit words in the Montgomery form x·2³⁸⁴ mod q, as the fp.Element types of
+// the 6-word fields.
+type Field struct {
+	q       [6]uint64
+	qInvNeg uint64    // -q⁻¹ mod 2⁶⁴
+	one     [6]uint64 // 2³⁸⁴ mod q
+}
+
+// NewField returns the field of modulus q. It panics if q is even, or not in
+// [3, 2³⁸³).
+func NewField(q *big.Int) *Field {
+	if q.Bit(0) == 0 || q.Cmp(big.NewInt(3)) < 0 || q.BitLen() > 383 {
+		panic("ct: invalid modulus")
+	}
+	f := new(Field)
+	toWords(&f.q, q)
+
+	// -q⁻¹ mod 2⁶⁴ by Newton iteration, each step doubles the number of
+	// correct low bits
+	inv := f.q[0]
+	for i := 0; i < 5; i++ {
+		inv *= 2 - f.q[0]*inv
+	}
+	f.qInvNeg = -inv
+
+	var r big.Int
+	r.Lsh(big.NewInt(1), 384).Mod(&r, q)
+	toWords(&f.one, &r)
+	return f
+}
+
+// Mul sets z to x·y·2⁻³⁸⁴ mod q, the Montgomery product of x and y, which must
+// be smaller than q. Its running time does not depend on x and y.
+func (f *Field) Mul(z, x, y *[6]uint64) {
+	// CIOS: t = (t + x·y[i] + m·q) / 2⁶⁴ with t < 2q
+	var t [8]uint64
+	for i := range y {
+		var carry, c uint64
+		for j := range x {
+			hi, lo := bits.Mul64(x[j], y[i])
+			lo, c = bits.Add64(lo, t[j], 0)
+			hi += c
+			t[j], c = bits.Add64(lo, carry, 0)
+			carry = hi + c
+		}
+		t[6], c = bits.Add64(t[6], carry, 0)
+		t[7] = c
+
+		m := t[0] * f.qInvNeg
+		hi, lo := bits.Mul64(m, f.q[0])
+		_, c = bits.Add64(lo, t[0], 0)
+		carry = hi + c
+		for j := 1; j < len(f.q); j++ {
+			hi, lo = bits.Mul64(m, f.q[j])
+			lo, c = bits.Add64(lo, t[j], 0)
+			hi += c
+			t[j-1], c = bits.Add64(lo, carry, 0)
+			carry = hi + c
+		}
+		t[5], c = bits.Add64(t[6], carry, 0)
+		t[6] = t[7] + c
+	}
+
+	// z = t - q if t ≥ q, selected with a mask
+	var s [6]uint64
+	var b uint64
+	for j := range s {
+		s[j], b = bits.Sub64(t[j], f.q[j], b)
+	}
+	_, b = bits.Sub64(t[6], 0, b)
+	mask := -b // all ones iff t < q
+	for j := range z {
+		z[j] = t[j]&mask | s[j]&^mask
+	}
+}
+
+// Exp sets z to x^e in the Montgomery form, with x smaller than q. It branches
+// on the bits of e, which must be public, but its running time does not depend
+// on x.
+func (f *Field) Exp(z, x *[6]uint64, e *big.Int) {
+	base := *x
+	r := f.one
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		f.Mul(&r, &r, &r)
+		if e.Bit(i) == 1 {
+			f.Mul(&r, &r, &base)
+		}
+	}
+	*z = r
+}
+
+// Equal returns 1 if x == y and 0 otherwise. Its running time does not depend
+// on x and y.
+func Equal(x, y *[6]uint64) uint64 {
+	var d uint64
+	for i := range x {
+		d |= x[i] ^ y[i]
+	}
+	// the top bit of d|-d is set iff d ≠ 0
+	return 1 ^ ((d | -d) >> 63)
+}
+
+// toWords sets z to the little-endian 64-bit words of 0 ≤ x < 2³⁸⁴.
+func toWords(z *[6]uint64, x *big.Int) {
+	*z = [6]uint64{}
+	for i, w := range x.Bits() {
+		z[i] = uint64(w)
+	}
+}
@@ -0,0 +1,128 @@
+package ct
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// testModuli returns odd moduli of several sizes up to 383 bits, among which
+// the base field of BLS12-381 and random primes.
+func testModuli(t *testing.T) []*big.Int {
+	p381, _ := new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+	moduli := []*big.Int{big.NewInt(3), big.NewInt(7), p381}
+	for _, size := range []int{64, 200, 377, 383} {
+		q, err := rand.Prime(rand.Reader, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moduli = append(moduli, q)
+	}
+	return moduli
+}
+
+// testElements returns 0, 1, q-1 and random elements of 𝔽q.
+func testElements(t *testing.T, q *big.Int) []*big.Int {
+	xs := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(q, big.NewInt(1))}
+	for i := 0; i < 20; i++ {
+		x, err := rand.Int(rand.Reader, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+func TestFieldMul(t *testing.T) {
+	t.Parallel()
+
+	for _, q := range testModuli(t) {
+		f := NewField(q)
+		var rInv big.Int
+		rInv.Lsh(big.NewInt(1), 384).ModInverse(&rInv, q)
+
+		xs := testElements(t, q)
+		for _, x := range xs {
+			for _, y := range xs {
+				var xw, yw, zw [6]uint64
+				toWords(&xw, x)
+				toWords(&yw, y)
+				f.Mul(&zw, &xw, &yw)
+
+				var want big.Int
+				want.Mul(x, y).Mul(&want, &rInv).Mod(&want, q)
+				var wantw [6]uint64
+				toWords(&wantw, &want)
+				if zw != wantw {
+					t.Fatalf("q=%v: Mul(%v, %v) is wrong", q, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestFieldExp(t *testing.T) {
+	t.Parallel()
+
+	for _, q := range testModuli(t) {
+		f := NewField(q)
+		var r big.Int
+		r.Lsh(big.NewInt(1), 384).Mod(&r, q)
+
+		var e big.Int
+		e.Sub(q, big.NewInt(1)).Rsh(&e, 1)
+		for _, exp := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), &e} {
+			for _, x := range testElements(t, q) {
+				// x·R is raised to x^e·R in the Montgomery form
+				var xr, want big.Int
+				xr.Mul(x, &r).Mod(&xr, q)
+				want.Exp(x, exp, q).Mul(&want, &r).Mod(&want, q)
+
+				var xw, zw, wantw [6]uint64
+				toWords(&xw, &xr)
+				toWords(&wantw, &want)
+				f.Exp(&zw, &xw, exp)
+				if zw != wantw {
+					t.Fatalf("q=%v: Exp(%v, %v) is wrong", q, x, exp)
+				}
+			}
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	x := [6]uint64{1, 2, 3, 4, 5, 6}
+	if Equal(&x, &x) != 1 {
+		t.Fatal("x should be equal to itself")
+	}
+	for i := range x {
+		for _, bit := range []uint{0, 63} {
+			y := x
+			y[i] ^= 1 << bit
+			if Equal(&x, &y) != 0 {
+				t.Fatalf("x should differ from y in word %d bit %d", i, bit)
+			}
+		}
+	}
+}
+
+func TestNewFieldInvalid(t *testing.T) {
+	t.Parallel()
+
+	// 2³⁸³+1 is too large
+	var large big.Int
+	large.Lsh(big.NewInt(1), 383).Add(&large, big.NewInt(1))
+	for _, q := range []*big.Int{big.NewInt(1), big.NewInt(4), &large} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("NewField(%v) should panic", q)
+				}
+			}()
+			NewField(q)
+		}()
+	}
+}
@@ -1,6 +1,7 @@
 package parallel
 
 import (
+	"context"
 	"runtime"
 	"sync"
 )
@@ -54,3 +55,20 @@ func Execute(nbIterations int, work func(int, int), maxCpus ...int) {
 
 	wg.Wait()
 }
+
+// ExecuteContext is like Execute but gives up when ctx is done: the tasks that
+// have not started yet are skipped and ctx.Err() is returned once the started
+// ones have returned. The work function should itself return early once ctx
+// is done.
+func ExecuteContext(ctx context.Context, nbIterations int, work func(int, int), maxCpus ...int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	Execute(nbIterations, func(start, end int) {
+		if ctx.Err() != nil {
+			return
+		}
+		work(start, end)
+	}, maxCpus...)
+	return ctx.Err()
+}